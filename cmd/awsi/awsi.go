@@ -3,21 +3,254 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"utils/aws/pkg/account"
+	"utils/aws/pkg/duration"
 	"utils/aws/pkg/ec2"
+	"utils/aws/pkg/pager"
+	"utils/aws/pkg/picker"
+	"utils/aws/pkg/table"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"golang.org/x/term"
+)
+
+const (
+	exitError              = 1
+	exitAccessDenied       = 3
+	exitExpiredCredentials = 4
+	exitThrottled          = 5
+	exitInvalidInstanceID  = 6
+	exitCancelled          = 130
 )
 
 type arguments struct {
-	noHeadings bool
-	tags       bool
-	less       bool
-	search     []string
+	noHeadings     bool
+	tags           bool
+	less           bool
+	limit          int
+	pageSize       int32
+	regions        []string
+	allRegions     bool
+	profiles       []string
+	roles          []account.Role
+	accountsFile   string
+	filters        []types.Filter
+	exclude        []string
+	regex          bool
+	fuzzy          bool
+	columns        []string
+	output         string
+	template       string
+	templateFile   string
+	query          string
+	sort           []table.SortKey
+	groupBy        string
+	color          string
+	wide           bool
+	tagKeys        []string
+	tagsAsColumns  bool
+	compactTags    bool
+	states         []string
+	running        bool
+	allStates      bool
+	launchedAfter  string
+	launchedBefore string
+	olderThan      string
+	newerThan      string
+	interactive    bool
+	pick           string
+	search         []string
+}
+
+// launchWindow combines the launch time flags, keeping the narrowest bounds
+func (a arguments) launchWindow(now time.Time) (after time.Time, before time.Time) {
+	if a.launchedAfter != "" {
+		after, _ = ec2.ParseTimeBound(a.launchedAfter, now)
+	}
+	if a.newerThan != "" {
+		age, _ := duration.Parse(a.newerThan)
+		if bound := now.Add(-age); bound.After(after) {
+			after = bound
+		}
+	}
+	if a.launchedBefore != "" {
+		before, _ = ec2.ParseTimeBound(a.launchedBefore, now)
+	}
+	if a.olderThan != "" {
+		age, _ := duration.Parse(a.olderThan)
+		if bound := now.Add(-age); before.IsZero() || bound.Before(before) {
+			before = bound
+		}
+	}
+	return after, before
+}
+
+// terminated and shutting-down instances are hidden, and counted, unless states
+// are chosen some other way
+func (a arguments) stateOptions() (states []string, hidden []string) {
+	switch {
+	case a.allStates:
+		return nil, nil
+	case a.running:
+		return []string{"running"}, nil
+	case len(a.states) > 0:
+		return a.states, nil
+	}
+	for _, f := range a.filters {
+		if *f.Name == "instance-state-name" || *f.Name == "instance-state-code" {
+			return nil, nil
+		}
+	}
+	return ec2.DefaultStates, ec2.HiddenStates
+}
+
+func (a arguments) nameMatch() ec2.MatchMode {
+	switch {
+	case a.regex:
+		return ec2.RegexMatch
+	case a.fuzzy:
+		return ec2.FuzzyMatch
+	}
+	return ec2.WildcardMatch
+}
+
+func (a arguments) columnNames() []string {
+	if len(a.columns) == 0 {
+		return ec2.DefaultColumns
+	}
+	return a.columns
+}
+
+func (a arguments) pickColumn() string {
+	if a.pick == "" {
+		return "id"
+	}
+	return a.pick
+}
+
+func (a arguments) outputName() string {
+	if a.output == "" {
+		return "table"
+	}
+	return a.output
+}
+
+// tableOutput is whether the results are shown as the fixed width table,
+// which has room for notes that would corrupt the other formats
+func (a arguments) tableOutput() bool {
+	return a.query == "" && a.template == "" && a.templateFile == "" && a.outputName() == "table"
+}
+
+func appendString(list *[]string) func(string) error {
+	return func(s string) error {
+		*list = append(*list, s)
+		return nil
+	}
+}
+
+// the leading ! of a search term is optional after -x, excluded terms
+// are stored without it
+func appendExclude(list *[]string) func(string) error {
+	return func(s string) error {
+		*list = append(*list, strings.TrimPrefix(s, "!"))
+		return nil
+	}
+}
+
+func appendFilter(filters *[]types.Filter) func(string) error {
+	return func(s string) error {
+		f, err := ec2.ParseFilter(s)
+		if err != nil {
+			return err
+		}
+		*filters = append(*filters, f)
+		return nil
+	}
+}
+
+func appendRoles(roles *[]account.Role) func(string) error {
+	return func(s string) error {
+		var arns []string
+		err := appendList(&arns)(s)
+		if err != nil {
+			return err
+		}
+		for _, arn := range arns {
+			role, err := account.ParseRole(arn)
+			if err != nil {
+				return err
+			}
+			*roles = append(*roles, role)
+		}
+		return nil
+	}
+}
+
+func appendList(list *[]string) func(string) error {
+	return func(s string) error {
+		for _, item := range strings.Split(s, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				return errors.New("empty list item")
+			}
+			*list = append(*list, item)
+		}
+		return nil
+	}
+}
+
+func appendColumns(columns *[]string) func(string) error {
+	return func(s string) error {
+		var names []string
+		err := appendList(&names)(s)
+		if err != nil {
+			return err
+		}
+		_, err = ec2.LookupColumns(names)
+		if err != nil {
+			return err
+		}
+		*columns = append(*columns, names...)
+		return nil
+	}
+}
+
+func parseOutput(a *arguments) func(string) error {
+	return func(s string) error {
+		if _, ok := table.Lookup(s); !ok {
+			return fmt.Errorf("must be one of %s", strings.Join(table.Names(), ", "))
+		}
+		a.output = s
+		return nil
+	}
+}
+
+func parsePageSize(a *arguments) func(string) error {
+	return func(s string) error {
+		size, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return err
+		}
+		if size != 0 && (size < 5 || size > 1000) {
+			return errors.New("must be between 5 and 1000, or 0 for the api default")
+		}
+		a.pageSize = int32(size)
+		return nil
+	}
 }
 
 func parseFlags(cmdName string, args []string) (arguments, string, error) {
@@ -25,26 +258,719 @@ func parseFlags(cmdName string, args []string) (arguments, string, error) {
 	var buf bytes.Buffer
 	flags := flag.NewFlagSet(cmdName, flag.ContinueOnError)
 	flags.Usage = func() {
-		fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s: [OPTIONS...] [name-tag-expression...] [instance-id...] [ami-id...]\n\n", os.Args[0])
-		fmt.Fprint(flag.CommandLine.Output(), "Find aws ec2 instances in an account.\nUse aws-vault or equivalent to provide credentials and select the account.\n\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s: [OPTIONS...] [name-tag-expression...] [tag-key=value-expression...] [tag-key=...] [instance-id...] [ami-id...] [ip-address...] [dns-name...] [cidr...] [!excluded-term...]\n\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "Find aws ec2 instances in an account.\nUse aws-vault or equivalent to provide credentials and select the account,\nor -profile, -role-arn or -accounts-file to search several accounts.\n\nA bare word always matches the Name tag, use tag-key= to find instances that have a tag\nwhatever its value. Several tag-key= terms must all be present.\n\nUse %s ssh or ssm [OPTIONS...] search-term... to connect to an instance.\n\n", os.Args[0])
 		flags.PrintDefaults()
 	}
 	flags.SetOutput(&buf)
 	flags.BoolVar(&a.noHeadings, "n", false, "")
 	flags.BoolVar(&a.noHeadings, "no-header", false, "do not output header")
+	flags.BoolVar(&a.less, "l", false, "")
+	flags.BoolVar(&a.less, "pager", false, "page output with $AWSI_PAGER, $PAGER or less, done automatically when it does not fit the terminal")
 	flags.BoolVar(&a.tags, "t", false, "")
-	flags.BoolVar(&a.tags, "tags", false, "print tags")
+	flags.BoolVar(&a.tags, "tags", false, "print tags, as a column per tag key for csv and tsv")
+	flags.IntVar(&a.limit, "limit", 0, "maximum number of instances to return per account and region, 0 for no limit")
+	flags.Func("page-size", "number of instances to request per api call, 5-1000 (default api maximum)", parsePageSize(&a))
+	flags.Func("region", "comma separated list of regions to search, may be repeated (default region from aws config)", appendList(&a.regions))
+	flags.BoolVar(&a.allRegions, "all-regions", false, "search all regions enabled for the account")
+	flags.Func("profile", "comma separated list of aws config profiles to search, may be repeated", appendList(&a.profiles))
+	flags.Func("role-arn", "comma separated list of role arns to assume and search, may be repeated", appendRoles(&a.roles))
+	flags.StringVar(&a.accountsFile, "accounts-file", "", "file listing a role arn and optional alias per line to assume and search")
+	flags.Func("filter", "ec2 describe-instances filter as name=value[,value...], may be repeated", appendFilter(&a.filters))
+	flags.Func("x", "", appendExclude(&a.exclude))
+	flags.Func("exclude", "remove instances matching a search term from the results, may be repeated, same as !term", appendExclude(&a.exclude))
+	flags.BoolVar(&a.regex, "regex", false, "treat name terms as case insensitive regular expressions matched client side")
+	flags.BoolVar(&a.fuzzy, "fuzzy", false, "match name terms fuzzily client side, ranking results by score")
+	flags.Func("o", "", appendColumns(&a.columns))
+	flags.Func("columns", "comma separated list of columns to print, may be repeated, a column name, tag:<key> or a preset (default, network)", appendColumns(&a.columns))
+	flags.Func("output", fmt.Sprintf("output format, one of %s (default table)", strings.Join(table.Names(), ", ")), parseOutput(&a))
+	flags.StringVar(&a.template, "template", "", "go text/template run once per instance, e.g. '{{.Name}} {{.PrivateIP}}'")
+	flags.StringVar(&a.templateFile, "template-file", "", "file containing a go text/template run once per instance")
+	flags.Func("query", "jmespath query over the describe-instances output, as aws cli --query, printed as json unless -output is given", parseQuery(&a))
+	flags.Func("sort", "comma separated list of columns to sort by, prefix with - for descending, e.g. name,-launched, may be repeated", appendSortKeys(&a.sort))
+	flags.Func("group-by", "column to group rows by, e.g. az, state or tag:team, printed as sections with counts", parseGroupBy(&a))
+	flags.Func("color", "colour table output: auto, always or never (default auto, off when NO_COLOR is set or stdout is not a terminal)", parseColor(&a))
+	flags.Func("tag-keys", "comma separated tag key globs to show, prefix with ! to hide, e.g. team,env or !aws:*, may be repeated", appendList(&a.tagKeys))
+	flags.BoolVar(&a.tagsAsColumns, "tags-as-columns", false, "show tags as a column per key")
+	flags.BoolVar(&a.compactTags, "compact-tags", false, "show tags as a single k=v,k=v column")
+	flags.Func("state", "comma separated list of instance states to show, may be repeated (default all but shutting-down and terminated)", appendStates(&a.states))
+	flags.BoolVar(&a.running, "running", false, "only show running instances, same as -state running")
+	flags.BoolVar(&a.allStates, "all", false, "show instances in every state, including shutting-down and terminated")
+	flags.Func("launched-after", "only instances launched after an RFC3339 time, or a duration ago such as 2h or 90d", parseTimeBound(&a.launchedAfter))
+	flags.Func("launched-before", "only instances launched before an RFC3339 time, or a duration ago such as 2h or 90d", parseTimeBound(&a.launchedBefore))
+	flags.Func("older-than", "only instances launched longer ago than a duration such as 90d or 3d4h", parseAge(&a.olderThan))
+	flags.Func("newer-than", "only instances launched within a duration such as 2h or 3d4h", parseAge(&a.newerThan))
+	flags.BoolVar(&a.interactive, "i", false, "")
+	flags.BoolVar(&a.interactive, "interactive", false, "pick instances in the terminal, type to filter and tab to select several, the chosen ones are printed to stdout")
+	flags.Func("pick", "column printed for instances chosen with -i, e.g. privateIp (default id)", parsePick(&a))
+	flags.BoolVar(&a.wide, "w", false, "")
+	flags.BoolVar(&a.wide, "wide", false, "do not cut table columns to fit the terminal width")
 	err := flags.Parse(args)
 	if err != nil {
 		return a, buf.String(), err
 	}
+	if a.allRegions && len(a.regions) > 0 {
+		err = errors.New("-region and -all-regions cannot be used together")
+		fmt.Fprintln(&buf, err)
+		return a, buf.String(), err
+	}
 	a.search = flags.Args()
+	err = validateCombinations(a)
+	if err != nil {
+		fmt.Fprintln(&buf, err)
+		return a, buf.String(), err
+	}
+	if a.regex && a.fuzzy {
+		err = errors.New("-regex and -fuzzy cannot be used together")
+		fmt.Fprintln(&buf, err)
+		return a, buf.String(), err
+	}
+	err = ec2.ValidateNameTerms(a.nameMatch(), ec2.FindNameSearchArgs(a.search))
+	if err != nil {
+		fmt.Fprintln(&buf, err)
+		return a, buf.String(), err
+	}
 	return a, buf.String(), nil
 }
 
+func parseQuery(a *arguments) func(string) error {
+	return func(s string) error {
+		err := ec2.ValidateQuery(s)
+		if err != nil {
+			return err
+		}
+		a.query = s
+		return nil
+	}
+}
+
+func appendSortKeys(keys *[]table.SortKey) func(string) error {
+	return func(s string) error {
+		parsed, err := table.ParseSortKeys(s)
+		if err != nil {
+			return err
+		}
+		for _, key := range parsed {
+			_, err = ec2.LookupColumns([]string{key.Column})
+			if err != nil {
+				return err
+			}
+		}
+		*keys = append(*keys, parsed...)
+		return nil
+	}
+}
+
+func parseGroupBy(a *arguments) func(string) error {
+	return func(s string) error {
+		_, err := ec2.LookupColumns([]string{s})
+		if err != nil {
+			return err
+		}
+		a.groupBy = s
+		return nil
+	}
+}
+
+func parsePick(a *arguments) func(string) error {
+	return func(s string) error {
+		_, err := ec2.LookupColumns([]string{s})
+		if err != nil {
+			return err
+		}
+		a.pick = s
+		return nil
+	}
+}
+
+func appendStates(states *[]string) func(string) error {
+	return func(s string) error {
+		var parsed []string
+		err := appendList(&parsed)(s)
+		if err != nil {
+			return err
+		}
+		err = ec2.ValidateStates(parsed)
+		if err != nil {
+			return err
+		}
+		*states = append(*states, parsed...)
+		return nil
+	}
+}
+
+func parseTimeBound(bound *string) func(string) error {
+	return func(s string) error {
+		_, err := ec2.ParseTimeBound(s, time.Now())
+		if err != nil {
+			return err
+		}
+		*bound = s
+		return nil
+	}
+}
+
+func parseAge(age *string) func(string) error {
+	return func(s string) error {
+		_, err := duration.Parse(s)
+		if err != nil {
+			return err
+		}
+		*age = s
+		return nil
+	}
+}
+
+func parseColor(a *arguments) func(string) error {
+	return func(s string) error {
+		switch s {
+		case "auto", "always", "never":
+			a.color = s
+			return nil
+		}
+		return errors.New("must be auto, always or never")
+	}
+}
+
+// NO_COLOR is overridden by an explicit -color=always
+func colorEnabled(mode string, noColor bool, terminal bool) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	return !noColor && terminal
+}
+
+func boolCount(values ...bool) int {
+	count := 0
+	for _, value := range values {
+		if value {
+			count++
+		}
+	}
+	return count
+}
+
+func validateCombinations(a arguments) error {
+	if a.template != "" && a.templateFile != "" {
+		return errors.New("-template and -template-file cannot be used together")
+	}
+	if (a.template != "" || a.templateFile != "") && a.output != "" {
+		return errors.New("-output cannot be used with a template")
+	}
+	if (a.template != "" || a.templateFile != "") && a.query != "" {
+		return errors.New("-query cannot be used with a template")
+	}
+	if a.query != "" && len(a.columns) > 0 {
+		return errors.New("-columns cannot be used with -query")
+	}
+	if (len(a.sort) > 0 || a.groupBy != "") && (a.query != "" || a.template != "" || a.templateFile != "") {
+		return errors.New("-sort and -group-by cannot be used with -query or a template")
+	}
+	if a.groupBy != "" && a.outputName() != "table" {
+		return errors.New("-group-by can only be used with table output")
+	}
+	if boolCount(len(a.states) > 0, a.running, a.allStates) > 1 {
+		return errors.New("-state, -running and -all cannot be used together")
+	}
+	if a.interactive && (a.query != "" || a.template != "" || a.templateFile != "" || a.output != "" || a.groupBy != "" || a.less) {
+		return errors.New("-i cannot be used with -query, a template, -output, -group-by or -pager")
+	}
+	if a.pick != "" && !a.interactive {
+		return errors.New("-pick can only be used with -i")
+	}
+	if a.tagsAsColumns && a.compactTags {
+		return errors.New("-tags-as-columns and -compact-tags cannot be used together")
+	}
+	if a.template != "" {
+		_, err := ec2.ParseTemplate(a.template)
+		return err
+	}
+	return nil
+}
+
+func loadTemplate(a arguments) (*template.Template, error) {
+	text := a.template
+	if a.templateFile != "" {
+		b, err := ioutil.ReadFile(a.templateFile)
+		if err != nil {
+			return nil, err
+		}
+		text = string(b)
+	}
+	return ec2.ParseTemplate(text)
+}
+
+func printQuery(w io.Writer, value interface{}, args arguments) error {
+	if args.output == "" || args.output == "json" {
+		b, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+	header, rows := ec2.QueryRows(value)
+	withHeader := header != nil && !args.noHeadings
+	// rows of a list of lists can differ in length, pad them to the widest
+	if header == nil {
+		width := 0
+		for _, row := range rows {
+			if len(row) > width {
+				width = len(row)
+			}
+		}
+		header = make([]string, width)
+	}
+	results := table.New(header)
+	for _, row := range rows {
+		padded := append(row, make([]string, len(header)-len(row))...)
+		err := results.AddRow(padded, []table.Tag{})
+		if err != nil {
+			return err
+		}
+	}
+	renderer, _ := table.Lookup(args.outputName())
+	return results.Render(w, renderer, table.Options{Header: withHeader})
+}
+
+func describeError(err error) (string, int) {
+	switch {
+	case errors.Is(err, ec2.ErrAccessDenied):
+		return fmt.Sprintf("access denied, the credentials in use are not allowed to describe instances in this account\n%v", err), exitAccessDenied
+	case errors.Is(err, ec2.ErrExpiredCredentials):
+		return fmt.Sprintf("credentials have expired, refresh them (e.g. start a new aws-vault session) and try again\n%v", err), exitExpiredCredentials
+	case errors.Is(err, ec2.ErrThrottled):
+		return fmt.Sprintf("requests are being throttled by aws, wait and try again or use a smaller --page-size\n%v", err), exitThrottled
+	case errors.Is(err, ec2.ErrInvalidInstanceID):
+		return fmt.Sprintf("one or more instance ids are malformed or do not exist in this account and region\n%v", err), exitInvalidInstanceID
+	}
+	return err.Error(), exitError
+}
+
+func reportErrors(stderr *log.Logger, results []ec2.Result) int {
+	exitCode := 0
+	for _, result := range results {
+		if result.Err == nil {
+			continue
+		}
+		message, code := describeError(result.Err)
+		if len(results) > 1 {
+			message = fmt.Sprintf("%s: %s", strings.TrimSpace(result.Account+" "+result.Region), message)
+		}
+		stderr.Println(message)
+		if exitCode == 0 {
+			exitCode = code
+		}
+	}
+	return exitCode
+}
+
+// hiddenNote is empty when no instances were hidden by state, the count is
+// from one page per region so may be a lower bound
+func hiddenNote(results []ec2.Result) string {
+	hidden, more := 0, false
+	for _, result := range results {
+		hidden += result.Hidden
+		more = more || result.HiddenMore
+	}
+	if hidden == 0 {
+		return ""
+	}
+	count := strconv.Itoa(hidden)
+	if more {
+		count += "+"
+	}
+	return fmt.Sprintf("%s shutting-down or terminated instances hidden, use -all to show them", count)
+}
+
+// reportHidden is for output other than the table, which ends with the note instead
+func reportHidden(stderr *log.Logger, results []ec2.Result) {
+	if note := hiddenNote(results); note != "" {
+		stderr.Print(note)
+	}
+}
+
+func loadAccounts(ctx context.Context, cfg aws.Config, args arguments) ([]account.Account, error) {
+	roles := args.roles
+	if args.accountsFile != "" {
+		f, err := os.Open(args.accountsFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		fileRoles, err := account.ReadAccountsFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", args.accountsFile, err)
+		}
+		roles = append(roles, fileRoles...)
+	}
+	accounts, err := account.FromProfiles(ctx, args.profiles)
+	if err != nil {
+		return nil, err
+	}
+	accounts = append(accounts, account.FromRoles(cfg, roles)...)
+	if len(accounts) == 0 {
+		accounts = []account.Account{{Config: cfg}}
+	}
+	return accounts, nil
+}
+
+// a single account needs no account column, so skip the extra sts and iam calls
+func identifyAccounts(ctx context.Context, accounts []account.Account) ([]account.Account, []ec2.Result) {
+	if len(accounts) < 2 {
+		return accounts, nil
+	}
+	errs := account.IdentifyAll(ctx, accounts)
+	identified := make([]account.Account, 0, len(accounts))
+	failed := make([]ec2.Result, 0)
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, ec2.Result{Account: accounts[i].Label(), Err: ec2.ClassifyError(err)})
+			continue
+		}
+		identified = append(identified, accounts[i])
+	}
+	return identified, failed
+}
+
+// highest score first, the score column is found by heading since columns
+// needed by -sort or -group-by can be added after it
+func rankByScore(t *table.FixedWidthFont, heading string) {
+	column := 0
+	for i, h := range t.Header {
+		if h == heading {
+			column = i
+		}
+	}
+	score := func(row []string) int {
+		value, _ := strconv.Atoi(row[column])
+		return value
+	}
+	t.SortRows(func(a []string, b []string) bool {
+		return score(a) > score(b)
+	})
+}
+
+// display is what is known about the terminal output goes to
+type display struct {
+	color bool
+	// 0 when output should not be cut to fit
+	width int
+}
+
+func detectDisplay(args arguments) display {
+	fd := int(os.Stdout.Fd())
+	terminal := term.IsTerminal(fd)
+	d := display{color: colorEnabled(args.color, os.Getenv("NO_COLOR") != "", terminal)}
+	if terminal && !args.wide {
+		width, _, err := term.GetSize(fd)
+		if err == nil {
+			d.width = width
+		}
+	}
+	return d
+}
+
+// render writes the results in the format chosen by args
+func render(w io.Writer, args arguments, results []ec2.Result, d display) error {
+	if args.query != "" {
+		value, err := ec2.Query(results, args.query)
+		if err != nil {
+			return err
+		}
+		return printQuery(w, value, args)
+	}
+	if args.template != "" || args.templateFile != "" {
+		tmpl, err := loadTemplate(args)
+		if err != nil {
+			return err
+		}
+		return ec2.ExecuteTemplate(w, tmpl, results)
+	}
+	instances, groupBy, withTags, err := instanceTable(args, results)
+	if err != nil {
+		return err
+	}
+	renderer, _ := table.Lookup(args.outputName())
+	opts := table.Options{Header: !args.noHeadings, Tags: withTags, MaxWidth: d.width, Keep: ec2.IdentifierColumns}
+	if d.color && args.outputName() == "table" {
+		opts.Styler = ec2.NewStyler(args.nameMatch(), ec2.FindNameSearchArgs(args.search))
+	}
+	if groupBy != "" {
+		groups, err := instances.GroupBy(groupBy)
+		if err != nil {
+			return err
+		}
+		err = table.RenderGroups(w, args.groupBy, groups, renderer, opts)
+	} else {
+		err = instances.Render(w, renderer, opts)
+	}
+	if err != nil || !args.tableOutput() {
+		return err
+	}
+	return printHiddenNote(w, results, d)
+}
+
+func printHiddenNote(w io.Writer, results []ec2.Result, d display) error {
+	note := hiddenNote(results)
+	if note == "" {
+		return nil
+	}
+	if d.color {
+		note = table.Colorize(table.Grey, note)
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n", note)
+	return err
+}
+
+// instanceTable is the sorted table of results with the columns and tags
+// chosen by args, withTags is whether the tags should be shown
+func instanceTable(args arguments, results []ec2.Result) (instances *table.FixedWidthFont, groupBy string, withTags bool, err error) {
+	columns, err := ec2.LookupColumns(args.columnNames())
+	if err != nil {
+		return nil, "", false, err
+	}
+	names := ec2.FindNameSearchArgs(args.search)
+	ranked := args.fuzzy && len(names) > 0
+	var score ec2.Column
+	if ranked {
+		score = ec2.ScoreColumn(names)
+		columns = append(columns, score)
+	}
+	sortKeys, groupBy, columns, err := arrangeColumns(args, columns)
+	if err != nil {
+		return nil, "", false, err
+	}
+	// json objects always include tags, -tags only changes the table layout
+	withTags = args.tags || len(args.tagKeys) > 0 || args.output == "json"
+	// the picker previews tags
+	instances, err = ec2.NewTable(results, columns, withTags || args.tagsAsColumns || args.compactTags || args.interactive)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if ranked {
+		rankByScore(instances, score.Heading)
+	}
+	err = instances.Sort(sortKeys)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if len(args.tagKeys) > 0 {
+		instances.FilterTags(ec2.TagKeyMatcher(args.tagKeys))
+	}
+	switch {
+	case args.tagsAsColumns:
+		instances.TagsAsColumns()
+		withTags = false
+	case args.compactTags:
+		instances.TagsAsColumn("tags")
+		withTags = false
+	}
+	return instances, groupBy, withTags, nil
+}
+
+// pick shows the instances in the picker and writes the -pick column of the
+// chosen ones to w
+func pick(w io.Writer, args arguments, results []ec2.Result) error {
+	instances, _, _, err := instanceTable(args, results)
+	if err != nil {
+		return err
+	}
+	if len(instances.Rows) == 0 {
+		return errors.New("no instances to pick from")
+	}
+	items, header, err := pickItems(instances)
+	if err != nil {
+		return err
+	}
+	chosen, err := picker.Run(os.Stdin, os.Stderr, items, picker.Options{Header: header, Score: pickScore})
+	if err != nil {
+		return err
+	}
+	found, _ := ec2.LookupColumns([]string{args.pickColumn()})
+	column := 0
+	for i, heading := range instances.Header {
+		if heading == found[0].Heading {
+			column = i
+		}
+	}
+	for _, i := range chosen {
+		_, err = fmt.Fprintln(w, instances.Rows[i][column])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pickItems are the table rows as rendered, with every column and tag of the
+// row in the preview
+func pickItems(instances *table.FixedWidthFont) ([]picker.Item, string, error) {
+	var buf bytes.Buffer
+	renderer, _ := table.Lookup("table")
+	err := instances.Render(&buf, renderer, table.Options{Header: true})
+	if err != nil {
+		return nil, "", err
+	}
+	// the header is followed by a blank line
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	header, rows := lines[0], lines[2:]
+	width := 0
+	for _, heading := range instances.Header {
+		if len(heading) > width {
+			width = len(heading)
+		}
+	}
+	items := make([]picker.Item, len(instances.Rows))
+	for i, row := range instances.Rows {
+		var detail strings.Builder
+		for j, heading := range instances.Header {
+			fmt.Fprintf(&detail, "%-*s  %s\n", width, heading, row[j])
+		}
+		for _, tag := range instances.Tags[i] {
+			fmt.Fprintf(&detail, "%s=%s\n", tag.Key, tag.Value)
+		}
+		line := strings.Join(row, " ")
+		// a cell with a line break would move the rendered rows out of step
+		if len(rows) == len(instances.Rows) {
+			line = rows[i]
+		}
+		items[i] = picker.Item{Line: line, Detail: detail.String()}
+	}
+	return items, header, nil
+}
+
+// pickScore needs every word of the query to fuzzy match a word of the line,
+// scoring words rather than the whole padded line keeps the length penalty fair
+func pickScore(query string, line string) int {
+	words := strings.Fields(line)
+	total := 0
+	for _, term := range strings.Fields(query) {
+		best := 0
+		for _, word := range words {
+			if score := ec2.FuzzyScore([]string{term}, word); score > best {
+				best = score
+			}
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return total
+}
+
+// arrangeColumns maps sort and group column names to headings, adding any
+// column that is not already shown
+func arrangeColumns(args arguments, columns []ec2.Column) ([]table.SortKey, string, []ec2.Column, error) {
+	heading := func(name string) (string, error) {
+		found, err := ec2.LookupColumns([]string{name})
+		if err != nil {
+			return "", err
+		}
+		for _, column := range columns {
+			if column.Heading == found[0].Heading {
+				return column.Heading, nil
+			}
+		}
+		columns = append(columns, found[0])
+		return found[0].Heading, nil
+	}
+	sortKeys := make([]table.SortKey, len(args.sort))
+	for i, key := range args.sort {
+		column, err := heading(key.Column)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		sortKeys[i] = table.SortKey{Column: column, Descending: key.Descending}
+	}
+	var groupBy string
+	if args.groupBy != "" {
+		column, err := heading(args.groupBy)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		groupBy = column
+	}
+	if args.interactive {
+		_, err := heading(args.pickColumn())
+		if err != nil {
+			return nil, "", nil, err
+		}
+	}
+	return sortKeys, groupBy, columns, nil
+}
+
+// page sends the output through a pager when asked to, or when stdout is a
+// terminal that the output does not fit on
+func page(less bool, render func(io.Writer) error) error {
+	var buf bytes.Buffer
+	if !less {
+		fd := int(os.Stdout.Fd())
+		if !term.IsTerminal(fd) {
+			return render(os.Stdout)
+		}
+		err := render(&buf)
+		if err != nil {
+			return err
+		}
+		width, height, err := term.GetSize(fd)
+		if err != nil || fitsScreen(buf.Bytes(), width, height) {
+			_, err = os.Stdout.Write(buf.Bytes())
+			return err
+		}
+		render = func(w io.Writer) error {
+			_, err := w.Write(buf.Bytes())
+			return err
+		}
+	}
+	p, err := pager.Start(pager.Command(), os.Stdout, os.Stderr)
+	if err != nil && !less {
+		// paging was not asked for, print directly when there is no pager
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	err = render(p)
+	closeErr := p.Close()
+	// the user quitting the pager before the end is not an error
+	if err != nil && !errors.Is(err, pager.ErrClosed) {
+		return err
+	}
+	return closeErr
+}
+
+// fitsScreen counts the terminal rows output takes up once long lines wrap,
+// one row is kept free for the shell prompt
+func fitsScreen(output []byte, width int, height int) bool {
+	rows := 0
+	for _, line := range strings.SplitAfter(string(output), "\n") {
+		if line == "" {
+			continue
+		}
+		rows++
+		lineWidth := table.VisibleWidth(strings.TrimSuffix(line, "\n"))
+		if width > 0 && lineWidth > width {
+			rows += (lineWidth - 1) / width
+		}
+	}
+	return rows < height
+}
+
 func main() {
 	noTimestamp := 0
 	stderr := log.New(os.Stderr, "", noTimestamp)
+	if len(os.Args) > 1 && os.Args[1] == "ssh" {
+		os.Exit(runSSH(os.Args[2:], stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "ssm" {
+		os.Exit(runSSM(os.Args[2:], stderr))
+	}
 	args, output, err := parseFlags(os.Args[0], os.Args[1:])
 	if err != nil && errors.Is(err, flag.ErrHelp) {
 		println(output)
@@ -58,10 +984,33 @@ func main() {
 	if err != nil {
 		stderr.Fatal(err)
 	}
-	instances := ec2.GetInstances(ctx, cfg, args.search)
-	table, err := ec2.Default(instances, args.tags)
+	accounts, err := loadAccounts(ctx, cfg, args)
+	if err != nil {
+		stderr.Fatal(err)
+	}
+	accounts, results := identifyAccounts(ctx, accounts)
+	states, hiddenStates := args.stateOptions()
+	opts := ec2.Options{Limit: args.limit, PageSize: args.pageSize, Filters: args.filters, NameMatch: args.nameMatch(), States: states, HiddenStates: hiddenStates}
+	opts.Exclude = append(ec2.FindExcludedArgs(args.search), args.exclude...)
+	opts.LaunchedAfter, opts.LaunchedBefore = args.launchWindow(time.Now())
+	results = append(results, ec2.GetInstancesInAccounts(ctx, accounts, args.regions, args.allRegions, args.search, opts)...)
+	exitCode := reportErrors(stderr, results)
+	if args.interactive {
+		err = pick(os.Stdout, args, results)
+		if errors.Is(err, picker.ErrCancelled) {
+			os.Exit(exitCancelled)
+		}
+	} else {
+		d := detectDisplay(args)
+		err = page(args.less, func(w io.Writer) error {
+			return render(w, args, results, d)
+		})
+	}
 	if err != nil {
 		stderr.Fatal(err)
 	}
-	table.Print(os.Stdout, !args.noHeadings, args.tags)
+	if args.interactive || !args.tableOutput() {
+		reportHidden(stderr, results)
+	}
+	os.Exit(exitCode)
 }
@@ -1,9 +1,22 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
+	"utils/aws/pkg/account"
+	"utils/aws/pkg/ec2"
+	"utils/aws/pkg/picker"
+	"utils/aws/pkg/table"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2sdk "github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
 
 func TestParseArgs(t *testing.T) {
@@ -26,6 +39,140 @@ func TestParseArgs(t *testing.T) {
 			arguments{tags: true, search: []string{"name"}}, ""},
 		{[]string{"--tags", "name"},
 			arguments{tags: true, search: []string{"name"}}, ""},
+		{[]string{"--limit", "20", "name"},
+			arguments{limit: 20, search: []string{"name"}}, ""},
+		{[]string{"--page-size", "100", "name"},
+			arguments{pageSize: 100, search: []string{"name"}}, ""},
+		{[]string{"--page-size", "0", "name"},
+			arguments{search: []string{"name"}}, ""},
+		{[]string{"--page-size", "2", "name"},
+			arguments{search: []string{}}, "invalid value \"2\" for flag -page-size: must be between 5 and 1000"},
+		{[]string{"--region", "us-east-1,eu-west-1", "name"},
+			arguments{regions: []string{"us-east-1", "eu-west-1"}, search: []string{"name"}}, ""},
+		{[]string{"--region", "us-east-1", "--region", "eu-west-1"},
+			arguments{regions: []string{"us-east-1", "eu-west-1"}, search: []string{}}, ""},
+		{[]string{"--all-regions", "name"},
+			arguments{allRegions: true, search: []string{"name"}}, ""},
+		{[]string{"--region", "us-east-1,,eu-west-1"},
+			arguments{search: []string{}}, "empty list item"},
+		{[]string{"--all-regions", "--region", "us-east-1"},
+			arguments{search: []string{}}, "-region and -all-regions cannot be used together"},
+		{[]string{"--profile", "dev,prod", "name"},
+			arguments{profiles: []string{"dev", "prod"}, search: []string{"name"}}, ""},
+		{[]string{"--role-arn", "arn:aws:iam::111111111111:role/ReadOnly,arn:aws:iam::222222222222:role/ReadOnly"},
+			arguments{roles: []account.Role{{ARN: "arn:aws:iam::111111111111:role/ReadOnly"}, {ARN: "arn:aws:iam::222222222222:role/ReadOnly"}}, search: []string{}}, ""},
+		{[]string{"--role-arn", "ReadOnly"},
+			arguments{search: []string{}}, "invalid role arn \"ReadOnly\""},
+		{[]string{"--accounts-file", "accounts.txt", "web"},
+			arguments{accountsFile: "accounts.txt", search: []string{"web"}}, ""},
+		{[]string{"--filter", "instance-state-name=running,stopped", "--filter", "vpc-id=vpc-123", "web"},
+			arguments{filters: []types.Filter{
+				{Name: aws.String("instance-state-name"), Values: []string{"running", "stopped"}},
+				{Name: aws.String("vpc-id"), Values: []string{"vpc-123"}},
+			}, search: []string{"web"}}, ""},
+		{[]string{"--filter", "vpcid=vpc-123"},
+			arguments{search: []string{}}, "invalid value \"vpcid=vpc-123\" for flag -filter: unknown filter \"vpcid\", did you mean \"vpc-id\"?"},
+		{[]string{"-x", "web-canary*", "--exclude", "state=terminated", "web-*", "!i-123"},
+			arguments{exclude: []string{"web-canary*", "state=terminated"}, search: []string{"web-*", "!i-123"}}, ""},
+		{[]string{"-x", "!web-canary*"}, arguments{exclude: []string{"web-canary*"}, search: []string{}}, ""},
+		{[]string{"--regex", "^web-[0-9]+$"},
+			arguments{regex: true, search: []string{"^web-[0-9]+$"}}, ""},
+		{[]string{"--fuzzy", "wbprd"},
+			arguments{fuzzy: true, search: []string{"wbprd"}}, ""},
+		{[]string{"--regex", "web-(", "i-123"},
+			arguments{search: []string{}}, "invalid name regular expression \"web-(\""},
+		{[]string{"--regex", "--fuzzy", "web"},
+			arguments{search: []string{}}, "-regex and -fuzzy cannot be used together"},
+		{[]string{"-o", "name,id,publicIp", "--columns", "tag:Owner", "web"},
+			arguments{columns: []string{"name", "id", "publicIp", "tag:Owner"}, search: []string{"web"}}, ""},
+		{[]string{"--columns", "default,vpc"},
+			arguments{columns: []string{"default", "vpc"}, search: []string{}}, ""},
+		{[]string{"--columns", "name,ip"},
+			arguments{search: []string{}}, "invalid value \"name,ip\" for flag -columns: unknown column \"ip\""},
+		{[]string{"--output", "json", "-o", "id,tag:Owner", "web"},
+			arguments{output: "json", columns: []string{"id", "tag:Owner"}, search: []string{"web"}}, ""},
+		{[]string{"--output", "csv", "-n", "-t"},
+			arguments{output: "csv", noHeadings: true, tags: true, search: []string{}}, ""},
+		{[]string{"--template", "{{.Name}} {{.PrivateIP}}", "web"},
+			arguments{template: "{{.Name}} {{.PrivateIP}}", search: []string{"web"}}, ""},
+		{[]string{"--template-file", "ssh.tmpl"},
+			arguments{templateFile: "ssh.tmpl", search: []string{}}, ""},
+		{[]string{"--template", "{{.Name"},
+			arguments{search: []string{}}, "unclosed action"},
+		{[]string{"--template", "{{.Name}}", "--template-file", "ssh.tmpl"},
+			arguments{search: []string{}}, "-template and -template-file cannot be used together"},
+		{[]string{"--template", "{{.Name}}", "--output", "json"},
+			arguments{search: []string{}}, "-output cannot be used with a template"},
+		{[]string{"--query", "Reservations[].Instances[].[InstanceId,State.Name]", "--output", "table"},
+			arguments{query: "Reservations[].Instances[].[InstanceId,State.Name]", output: "table", search: []string{}}, ""},
+		{[]string{"--query", "Reservations[.Instances"},
+			arguments{search: []string{}}, "invalid value \"Reservations[.Instances\" for flag -query: invalid query"},
+		{[]string{"--query", "Reservations", "--template", "{{.Name}}"},
+			arguments{search: []string{}}, "-query cannot be used with a template"},
+		{[]string{"--query", "Reservations", "-o", "id"},
+			arguments{search: []string{}}, "-columns cannot be used with -query"},
+		{[]string{"-l", "web"},
+			arguments{less: true, search: []string{"web"}}, ""},
+		{[]string{"--pager", "web"},
+			arguments{less: true, search: []string{"web"}}, ""},
+		{[]string{"--sort", "name,-launched", "--sort", "tag:team"},
+			arguments{sort: []table.SortKey{{Column: "name"}, {Column: "launched", Descending: true}, {Column: "tag:team"}}, search: []string{}}, ""},
+		{[]string{"--sort", "nmae"},
+			arguments{search: []string{}}, "invalid value \"nmae\" for flag -sort: unknown column \"nmae\""},
+		{[]string{"--group-by", "az"},
+			arguments{groupBy: "az", search: []string{}}, ""},
+		{[]string{"--group-by", "az", "--output", "json"},
+			arguments{search: []string{}}, "-group-by can only be used with table output"},
+		{[]string{"--sort", "name", "--query", "Reservations"},
+			arguments{search: []string{}}, "-sort and -group-by cannot be used with -query or a template"},
+		{[]string{"--color", "always", "web"},
+			arguments{color: "always", search: []string{"web"}}, ""},
+		{[]string{"--color=never"},
+			arguments{color: "never", search: []string{}}, ""},
+		{[]string{"--color", "sometimes"},
+			arguments{search: []string{}}, "invalid value \"sometimes\" for flag -color: must be auto, always or never"},
+		{[]string{"-w", "web"},
+			arguments{wide: true, search: []string{"web"}}, ""},
+		{[]string{"--wide", "web"},
+			arguments{wide: true, search: []string{"web"}}, ""},
+		{[]string{"--tag-keys", "team,env", "--tag-keys", "!aws:*", "--tags-as-columns"},
+			arguments{tagKeys: []string{"team", "env", "!aws:*"}, tagsAsColumns: true, search: []string{}}, ""},
+		{[]string{"--compact-tags"},
+			arguments{compactTags: true, search: []string{}}, ""},
+		{[]string{"--tags-as-columns", "--compact-tags"},
+			arguments{search: []string{}}, "-tags-as-columns and -compact-tags cannot be used together"},
+		{[]string{"--state", "running,stopped", "web"},
+			arguments{states: []string{"running", "stopped"}, search: []string{"web"}}, ""},
+		{[]string{"--running"},
+			arguments{running: true, search: []string{}}, ""},
+		{[]string{"--all"},
+			arguments{allStates: true, search: []string{}}, ""},
+		{[]string{"--state", "stoped"},
+			arguments{search: []string{}}, "invalid value \"stoped\" for flag -state: unknown instance state \"stoped\""},
+		{[]string{"--running", "--all"},
+			arguments{search: []string{}}, "-state, -running and -all cannot be used together"},
+		{[]string{"--launched-after", "2h", "--launched-before", "2021-09-26T19:00:00Z"},
+			arguments{launchedAfter: "2h", launchedBefore: "2021-09-26T19:00:00Z", search: []string{}}, ""},
+		{[]string{"--older-than", "90d", "--newer-than", "1w"},
+			arguments{olderThan: "90d", newerThan: "1w", search: []string{}}, ""},
+		{[]string{"--launched-after", "yesterday"},
+			arguments{search: []string{}}, "invalid value \"yesterday\" for flag -launched-after: invalid time \"yesterday\""},
+		{[]string{"--older-than", "2021-09-26T19:00:00Z"},
+			arguments{search: []string{}}, "invalid value \"2021-09-26T19:00:00Z\" for flag -older-than: invalid duration"},
+		{[]string{"-i", "--pick", "privateIp", "web"},
+			arguments{interactive: true, pick: "privateIp", search: []string{"web"}}, ""},
+		{[]string{"--interactive"},
+			arguments{interactive: true, search: []string{}}, ""},
+		{[]string{"--pick", "privateIp"},
+			arguments{search: []string{}}, "-pick can only be used with -i"},
+		{[]string{"--pick", "ip"},
+			arguments{search: []string{}}, "invalid value \"ip\" for flag -pick: unknown column \"ip\""},
+		{[]string{"-i", "--output", "json"},
+			arguments{search: []string{}}, "-i cannot be used with -query, a template, -output, -group-by or -pager"},
+		{[]string{"--output", "yaml"},
+			arguments{search: []string{}}, "invalid value \"yaml\" for flag -output: must be one of csv, json, table, tsv"},
+		{[]string{"--page-size", "lots", "name"},
+			arguments{search: []string{}}, "invalid value \"lots\" for flag -page-size"},
 	}
 	for _, d := range data {
 		t.Run(strings.Join(d.args, " "), func(t *testing.T) {
@@ -48,3 +195,430 @@ func TestParseArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestDescribeError(t *testing.T) {
+	var data = []struct {
+		err             error
+		expectedCode    int
+		expectedMessage string
+	}{
+		{ec2.Error{Kind: ec2.ErrAccessDenied, Err: errors.New("api error")}, exitAccessDenied, "access denied"},
+		{ec2.Error{Kind: ec2.ErrExpiredCredentials, Err: errors.New("api error")}, exitExpiredCredentials, "credentials have expired"},
+		{ec2.Error{Kind: ec2.ErrThrottled, Err: errors.New("api error")}, exitThrottled, "throttled"},
+		{ec2.Error{Kind: ec2.ErrInvalidInstanceID, Err: errors.New("api error")}, exitInvalidInstanceID, "instance ids are malformed"},
+		{errors.New("something else"), exitError, "something else"},
+	}
+	for _, d := range data {
+		t.Run(d.err.Error(), func(t *testing.T) {
+			message, code := describeError(d.err)
+			if code != d.expectedCode {
+				t.Errorf("code got %d, want %d", code, d.expectedCode)
+			}
+			if !strings.Contains(message, d.expectedMessage) {
+				t.Errorf("message got %q, want it to contain %q", message, d.expectedMessage)
+			}
+			if !strings.Contains(message, d.err.Error()) {
+				t.Errorf("message got %q, want it to contain the original error %q", message, d.err.Error())
+			}
+		})
+	}
+}
+
+func TestReportErrors(t *testing.T) {
+	var data = []struct {
+		testName       string
+		results        []ec2.Result
+		expectedCode   int
+		expectedOutput []string
+	}{
+		{"no errors", []ec2.Result{{Region: "us-east-1"}, {Region: "eu-west-1"}}, 0, []string{}},
+		{"single region", []ec2.Result{{Region: "us-east-1", Err: errors.New("boom")}}, exitError, []string{"boom"}},
+		{"one of several accounts", []ec2.Result{
+			{Account: "dev", Err: errors.New("no credentials")},
+			{Account: "111111111111 (prod)", Region: "us-east-1"},
+		}, exitError, []string{"dev: no credentials"}},
+		{"one of several regions", []ec2.Result{
+			{Region: "us-east-1"},
+			{Region: "eu-west-1", Err: ec2.Error{Kind: ec2.ErrAccessDenied, Err: errors.New("denied")}},
+			{Region: "ap-south-1", Err: errors.New("timeout")},
+		}, exitAccessDenied, []string{"eu-west-1: access denied", "ap-south-1: timeout"}},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			var buf bytes.Buffer
+			code := reportErrors(log.New(&buf, "", 0), d.results)
+			if code != d.expectedCode {
+				t.Errorf("code got %d, want %d", code, d.expectedCode)
+			}
+			for _, expected := range d.expectedOutput {
+				if !strings.Contains(buf.String(), expected) {
+					t.Errorf("output got %q, want it to contain %q", buf.String(), expected)
+				}
+			}
+			if len(d.expectedOutput) == 0 && buf.Len() != 0 {
+				t.Errorf("output got %q, want empty", buf.String())
+			}
+		})
+	}
+}
+
+func TestRankByScore(t *testing.T) {
+	instances := table.New([]string{"name", "score", "vpc"})
+	instances.AddRow([]string{"web-2", "3", "vpc-9"}, []table.Tag{})
+	instances.AddRow([]string{"web-10", "12", "vpc-1"}, []table.Tag{})
+	instances.AddRow([]string{"db", "7", "vpc-5"}, []table.Tag{})
+	rankByScore(&instances, "score")
+	expected := [][]string{{"web-10", "12", "vpc-1"}, {"db", "7", "vpc-5"}, {"web-2", "3", "vpc-9"}}
+	if !reflect.DeepEqual(instances.Rows, expected) {
+		t.Errorf("rows got %v, want %v", instances.Rows, expected)
+	}
+}
+
+func TestRenderFuzzyGroupBy(t *testing.T) {
+	instance := func(name string) types.Instance {
+		return types.Instance{
+			InstanceId: aws.String("i-" + name),
+			VpcId:      aws.String("vpc-1"),
+			Tags:       []types.Tag{{Key: aws.String("Name"), Value: aws.String(name)}},
+		}
+	}
+	output := &ec2sdk.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: []types.Instance{
+		instance("zzwzzzezzzzbzz"),
+		instance("web"),
+	}}}}
+	results := []ec2.Result{{Region: "us-east-1", Output: output}}
+	// the vpc column is added after the score, which must still decide the order
+	args := arguments{fuzzy: true, groupBy: "vpc", columns: []string{"name"}, search: []string{"web"}}
+	var buf bytes.Buffer
+	err := render(&buf, args, results, display{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Index(buf.String(), "web") > strings.Index(buf.String(), "zzwzzzezzzzbzz") {
+		t.Errorf("output got:\n%s, want web ranked first", buf.String())
+	}
+}
+
+func TestRenderNarrowKeepsIDs(t *testing.T) {
+	output := &ec2sdk.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: []types.Instance{{
+		InstanceId:       aws.String("i-0123456789abcdef0"),
+		PrivateIpAddress: aws.String("10.100.200.250"),
+		Tags:             []types.Tag{{Key: aws.String("Name"), Value: aws.String("web-production-frontend-1")}},
+	}}}}}
+	results := []ec2.Result{{Region: "us-east-1", Output: output}}
+	args := arguments{columns: []string{"name", "id", "privateIp"}}
+	var buf bytes.Buffer
+	err := render(&buf, args, results, display{width: 50})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "web-production… i-0123456789abcdef0 10.100.200.250") {
+		t.Errorf("output got:\n%s, want only the name cut", buf.String())
+	}
+}
+
+func TestPrintQuery(t *testing.T) {
+	value := []interface{}{[]interface{}{"i-1", "running"}, []interface{}{"i-2"}}
+	objects := []interface{}{map[string]interface{}{"id": "i-1", "state": "running"}}
+	var data = []struct {
+		testName string
+		value    interface{}
+		args     arguments
+		expected string
+	}{
+		{"json by default", []interface{}{"i-1"}, arguments{}, "[\n  \"i-1\"\n]\n"},
+		{"table pads rows", value, arguments{output: "table"}, "i-1 running\ni-2        \n"},
+		{"csv of objects has a header", objects, arguments{output: "csv"}, "id,state\ni-1,running\n"},
+		{"no header", objects, arguments{output: "csv", noHeadings: true}, "i-1,running\n"},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := printQuery(&buf, d.value, d.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != d.expected {
+				t.Errorf("output got %q, want %q", buf.String(), d.expected)
+			}
+		})
+	}
+}
+
+func TestFitsScreen(t *testing.T) {
+	var data = []struct {
+		output   string
+		width    int
+		height   int
+		expected bool
+	}{
+		{"", 80, 24, true},
+		{"a\nb\n", 80, 3, true},
+		{"a\nb\nc\n", 80, 3, false},
+		{"a\nb\nc", 80, 3, false},
+		{strings.Repeat("line\n", 100), 80, 50, false},
+		{strings.Repeat("x", 10) + "\n", 10, 2, true},
+		{strings.Repeat("x", 11) + "\n", 10, 2, false},
+		{"\x1b[32m" + strings.Repeat("x", 10) + "\x1b[0m\n", 10, 2, true},
+		{strings.Repeat("x", 200) + "\nb\n", 80, 4, false},
+		{strings.Repeat("x", 200) + "\nb\n", 80, 5, true},
+	}
+	for _, d := range data {
+		t.Run(fmt.Sprintf("%d lines in %dx%d", strings.Count(d.output, "\n"), d.width, d.height), func(t *testing.T) {
+			if fits := fitsScreen([]byte(d.output), d.width, d.height); fits != d.expected {
+				t.Errorf("fits got %v, want %v", fits, d.expected)
+			}
+		})
+	}
+}
+
+func TestArrangeColumns(t *testing.T) {
+	columns, _ := ec2.LookupColumns([]string{"name", "id"})
+	args := arguments{sort: []table.SortKey{{Column: "name"}, {Column: "launched", Descending: true}}, groupBy: "tag:team"}
+	sortKeys, groupBy, arranged, err := arrangeColumns(args, columns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedKeys := []table.SortKey{{Column: "name"}, {Column: "launched", Descending: true}}
+	if !reflect.DeepEqual(sortKeys, expectedKeys) {
+		t.Errorf("sort keys got %+v, want %+v", sortKeys, expectedKeys)
+	}
+	if groupBy != "team" {
+		t.Errorf("group by got %q, want %q", groupBy, "team")
+	}
+	headings := make([]string, len(arranged))
+	for i, column := range arranged {
+		headings[i] = column.Heading
+	}
+	expectedHeadings := []string{"name", "id", "launched", "team"}
+	if !reflect.DeepEqual(headings, expectedHeadings) {
+		t.Errorf("headings got %v, want %v", headings, expectedHeadings)
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	var data = []struct {
+		mode     string
+		noColor  bool
+		terminal bool
+		expected bool
+	}{
+		{"", false, true, true},
+		{"", false, false, false},
+		{"", true, true, false},
+		{"auto", false, true, true},
+		{"always", true, false, true},
+		{"never", false, true, false},
+	}
+	for _, d := range data {
+		t.Run(fmt.Sprintf("%q %v %v", d.mode, d.noColor, d.terminal), func(t *testing.T) {
+			if enabled := colorEnabled(d.mode, d.noColor, d.terminal); enabled != d.expected {
+				t.Errorf("enabled got %v, want %v", enabled, d.expected)
+			}
+		})
+	}
+}
+
+func TestStateOptions(t *testing.T) {
+	var data = []struct {
+		testName       string
+		args           arguments
+		expectedStates []string
+		expectedHidden []string
+	}{
+		{"default hides terminated", arguments{}, ec2.DefaultStates, ec2.HiddenStates},
+		{"all", arguments{allStates: true}, nil, nil},
+		{"running", arguments{running: true}, []string{"running"}, nil},
+		{"state", arguments{states: []string{"terminated"}}, []string{"terminated"}, nil},
+		{"state filter", arguments{filters: []types.Filter{{Name: aws.String("instance-state-name"), Values: []string{"stopped"}}}}, nil, nil},
+		{"other filter", arguments{filters: []types.Filter{{Name: aws.String("vpc-id"), Values: []string{"vpc-1"}}}}, ec2.DefaultStates, ec2.HiddenStates},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			states, hidden := d.args.stateOptions()
+			if !reflect.DeepEqual(states, d.expectedStates) {
+				t.Errorf("states got %v, want %v", states, d.expectedStates)
+			}
+			if !reflect.DeepEqual(hidden, d.expectedHidden) {
+				t.Errorf("hidden got %v, want %v", hidden, d.expectedHidden)
+			}
+		})
+	}
+}
+
+func TestReportHidden(t *testing.T) {
+	var data = []struct {
+		results  []ec2.Result
+		expected string
+	}{
+		{[]ec2.Result{{Region: "us-east-1"}}, ""},
+		{[]ec2.Result{{Region: "us-east-1", Hidden: 2}, {Region: "eu-west-1", Hidden: 1}},
+			"3 shutting-down or terminated instances hidden, use -all to show them\n"},
+		{[]ec2.Result{{Region: "us-east-1", Hidden: 1000, HiddenMore: true}, {Region: "eu-west-1", Hidden: 1}},
+			"1001+ shutting-down or terminated instances hidden, use -all to show them\n"},
+	}
+	for _, d := range data {
+		t.Run(d.expected, func(t *testing.T) {
+			var buf bytes.Buffer
+			reportHidden(log.New(&buf, "", 0), d.results)
+			if buf.String() != d.expected {
+				t.Errorf("output got %q, want %q", buf.String(), d.expected)
+			}
+		})
+	}
+}
+
+func TestRenderHiddenNote(t *testing.T) {
+	output := &ec2sdk.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: []types.Instance{{
+		InstanceId: aws.String("i-1"),
+		Tags:       []types.Tag{{Key: aws.String("Name"), Value: aws.String("web")}},
+	}}}}}
+	results := []ec2.Result{{Region: "us-east-1", Output: output, Hidden: 2}}
+	note := "2 shutting-down or terminated instances hidden, use -all to show them"
+	var data = []struct {
+		testName string
+		args     arguments
+		expected bool
+	}{
+		{"table", arguments{columns: []string{"name", "id"}}, true},
+		{"grouped table", arguments{columns: []string{"name", "id"}, groupBy: "name"}, true},
+		{"json", arguments{output: "json"}, false},
+		{"csv", arguments{output: "csv"}, false},
+		{"query", arguments{query: "[].id"}, false},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := render(&buf, d.args, results, display{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ends := strings.HasSuffix(buf.String(), "\n\n"+note+"\n"); ends != d.expected {
+				t.Errorf("output got:\n%s, want note at the end %v", buf.String(), d.expected)
+			}
+			if d.args.tableOutput() != d.expected {
+				t.Errorf("tableOutput got %v, want %v", d.args.tableOutput(), d.expected)
+			}
+		})
+	}
+}
+
+func TestLaunchWindow(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2021-09-26T19:00:00Z")
+	format := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	var data = []struct {
+		testName       string
+		args           arguments
+		expectedAfter  string
+		expectedBefore string
+	}{
+		{"none", arguments{}, "", ""},
+		{"newer than", arguments{newerThan: "2h"}, "2021-09-26T17:00:00Z", ""},
+		{"older than", arguments{olderThan: "1d"}, "", "2021-09-25T19:00:00Z"},
+		{"time bounds", arguments{launchedAfter: "2021-09-01T00:00:00Z", launchedBefore: "3d"}, "2021-09-01T00:00:00Z", "2021-09-23T19:00:00Z"},
+		{"narrowest after", arguments{launchedAfter: "2021-09-01T00:00:00Z", newerThan: "2h"}, "2021-09-26T17:00:00Z", ""},
+		{"narrowest before", arguments{launchedBefore: "2021-09-01T00:00:00Z", olderThan: "2h"}, "", "2021-09-01T00:00:00Z"},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			after, before := d.args.launchWindow(now)
+			if format(after) != d.expectedAfter {
+				t.Errorf("after got %q, want %q", format(after), d.expectedAfter)
+			}
+			if format(before) != d.expectedBefore {
+				t.Errorf("before got %q, want %q", format(before), d.expectedBefore)
+			}
+		})
+	}
+}
+
+func TestPickScore(t *testing.T) {
+	line := "web-1  i-0123  10.0.0.1  running"
+	var data = []struct {
+		query   string
+		matches bool
+	}{
+		{"web", true},
+		{"web run", true},
+		{"w1", true},
+		{"web stopped", false},
+		{"db", false},
+	}
+	for _, d := range data {
+		t.Run(d.query, func(t *testing.T) {
+			score := pickScore(d.query, line)
+			if (score > 0) != d.matches {
+				t.Errorf("score got %d, want match %v", score, d.matches)
+			}
+		})
+	}
+	if pickScore("web", "web-1") <= pickScore("web", "my-web-server-1") {
+		t.Errorf("want the closer name to score higher")
+	}
+}
+
+func TestPickItems(t *testing.T) {
+	instances := table.New([]string{"name", "id"})
+	_ = instances.AddRow([]string{"web-1", "i-1"}, []table.Tag{{Key: "team", Value: "a"}})
+	_ = instances.AddRow([]string{"database", "i-2"}, []table.Tag{})
+	items, header, err := pickItems(&instances)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if header != "name     id " {
+		t.Errorf("header got %q, want %q", header, "name     id ")
+	}
+	expected := []picker.Item{
+		{Line: "web-1    i-1", Detail: "name  web-1\nid    i-1\nteam=a\n"},
+		{Line: "database i-2", Detail: "name  database\nid    i-2\n"},
+	}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("items got %q, want %q", items, expected)
+	}
+}
+
+func TestInstanceTableRanksWithPickColumn(t *testing.T) {
+	instance := func(name string) types.Instance {
+		return types.Instance{
+			InstanceId: aws.String("i-" + name),
+			VpcId:      aws.String("vpc-1"),
+			Tags:       []types.Tag{{Key: aws.String("Name"), Value: aws.String(name)}},
+		}
+	}
+	output := &ec2sdk.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: []types.Instance{
+		instance("zzwzzzezzzzbzz"),
+		instance("web"),
+	}}}}
+	results := []ec2.Result{{Region: "us-east-1", Output: output}}
+	args := arguments{fuzzy: true, interactive: true, pick: "vpc", columns: []string{"name"}, search: []string{"web"}}
+	instances, _, _, err := instanceTable(args, results)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if instances.Header[len(instances.Header)-1] != "vpc" {
+		t.Fatalf("header got %v, want vpc added after score", instances.Header)
+	}
+	if instances.Rows[0][0] != "web" {
+		t.Errorf("rows got %v, want web ranked first", instances.Rows)
+	}
+}
+
+func TestArrangeColumnsAddsPick(t *testing.T) {
+	columns, err := ec2.LookupColumns([]string{"name"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	args := arguments{interactive: true, pick: "privateIp"}
+	_, _, arranged, err := arrangeColumns(args, columns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(arranged) != 2 || arranged[1].Heading != "privateIp" {
+		t.Errorf("columns got %+v, want name and privateIp", arranged)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"utils/aws/pkg/ec2"
+	"utils/aws/pkg/remote"
+	"utils/aws/pkg/table"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+type sshArguments struct {
+	profile string
+	region  string
+	user    string
+	key     string
+	address []string
+	config  string
+	search  []string
+	sshArgs []string
+}
+
+func parseAddressKinds(kinds *[]string) func(string) error {
+	return func(s string) error {
+		var parsed []string
+		err := appendList(&parsed)(s)
+		if err != nil {
+			return err
+		}
+		err = remote.ValidateAddressKinds(parsed)
+		if err != nil {
+			return err
+		}
+		*kinds = parsed
+		return nil
+	}
+}
+
+// arguments after -- are passed to ssh
+func parseSSHFlags(cmdName string, args []string) (sshArguments, string, error) {
+	var a sshArguments
+	var buf bytes.Buffer
+	flags := flag.NewFlagSet(cmdName, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage of %s ssh: [OPTIONS...] search-term... [-- ssh-arguments...]\n\n", os.Args[0])
+		fmt.Fprint(flags.Output(), "Find a running instance and connect to it with ssh, asking which one when several match.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.SetOutput(&buf)
+	flags.StringVar(&a.profile, "profile", "", "aws config profile to search")
+	flags.StringVar(&a.region, "region", "", "region to search (default region from aws config)")
+	flags.StringVar(&a.user, "l", "", "")
+	flags.StringVar(&a.user, "user", "", "user to log in as (default from the awsi config, then ssh)")
+	flags.StringVar(&a.key, "i", "", "")
+	flags.StringVar(&a.key, "key", "", "private key file (default from the awsi config, then ssh)")
+	flags.Func("address", "comma separated address kinds to try in order: private, public, private-dns, public-dns (default private,public)", parseAddressKinds(&a.address))
+	flags.StringVar(&a.config, "config", remote.DefaultConfigPath(), "awsi config file with users and keys per tag or ami")
+	for i, arg := range args {
+		if arg == "--" {
+			a.sshArgs = args[i+1:]
+			args = args[:i]
+			break
+		}
+	}
+	err := flags.Parse(args)
+	if err != nil {
+		return a, buf.String(), err
+	}
+	a.search = flags.Args()
+	if len(a.search) == 0 {
+		err = errors.New("a search term is needed to find the instance")
+		fmt.Fprintln(&buf, err)
+		return a, buf.String(), err
+	}
+	return a, buf.String(), nil
+}
+
+// chooseInstance asks on out which instance to use, reading the answer from in
+func chooseInstance(in io.Reader, out io.Writer, views []ec2.View) (ec2.View, error) {
+	if len(views) == 1 {
+		return views[0], nil
+	}
+	choices := table.New([]string{"", "name", "id", "privateIp", "az"})
+	for i, view := range views {
+		_ = choices.AddRow([]string{strconv.Itoa(i+1) + ")", view.Name, view.InstanceID, view.PrivateIP, view.AZ}, []table.Tag{})
+	}
+	choices.Print(out, false, false)
+	fmt.Fprintf(out, "choose an instance [1-%d]: ", len(views))
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		return ec2.View{}, errors.New("no instance chosen")
+	}
+	choice, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || choice < 1 || choice > len(views) {
+		return ec2.View{}, fmt.Errorf("invalid choice %q", strings.TrimSpace(line))
+	}
+	return views[choice-1], nil
+}
+
+// loadRemoteConfig is the aws config for the ssh and ssm subcommands, which
+// search a single account and region
+func loadRemoteConfig(ctx context.Context, profile string, region string) (aws.Config, error) {
+	var opts []func(*config.LoadOptions) error
+	if profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(profile))
+	}
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+	return config.LoadDefaultConfig(ctx, opts...)
+}
+
+// sshLogin applies the flags over the awsi config
+func sshLogin(args sshArguments, remoteConfig remote.Config, view ec2.View) (user string, key string, kinds []string) {
+	user, key = remoteConfig.Login(view)
+	if args.user != "" {
+		user = args.user
+	}
+	if args.key != "" {
+		key = args.key
+	}
+	kinds = remoteConfig.Address
+	if len(args.address) > 0 {
+		kinds = args.address
+	}
+	return user, key, kinds
+}
+
+// findInstance looks up running instances matching the search and picks one
+func findInstance(ctx context.Context, cfg aws.Config, search []string) (ec2.View, error) {
+	output, err := ec2.GetInstances(ctx, cfg, search, ec2.Options{States: []string{"running"}, Exclude: ec2.FindExcludedArgs(search)})
+	if err != nil {
+		return ec2.View{}, err
+	}
+	views := ec2.Views([]ec2.Result{{Region: cfg.Region, Output: output}})
+	if len(views) == 0 {
+		return ec2.View{}, fmt.Errorf("no running instances match %s", strings.Join(search, " "))
+	}
+	return chooseInstance(os.Stdin, os.Stderr, views)
+}
+
+// runSSH returns the exit code of ssh, or of awsi when ssh could not be run
+func runSSH(cmdArgs []string, stderr *log.Logger) int {
+	args, output, err := parseSSHFlags(os.Args[0], cmdArgs)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(os.Stderr, output)
+		return 0
+	}
+	if err != nil {
+		stderr.Print(strings.TrimSpace(output))
+		return exitError
+	}
+	remoteConfig, err := remote.LoadConfig(args.config)
+	if err != nil {
+		stderr.Print(err)
+		return exitError
+	}
+	ctx := context.Background()
+	cfg, err := loadRemoteConfig(ctx, args.profile, args.region)
+	if err != nil {
+		stderr.Print(err)
+		return exitError
+	}
+	view, err := findInstance(ctx, cfg, args.search)
+	if err != nil {
+		message, code := describeError(err)
+		stderr.Print(message)
+		return code
+	}
+	user, key, kinds := sshLogin(args, remoteConfig, view)
+	address, err := remote.Address(view, kinds)
+	if err != nil {
+		stderr.Print(err)
+		return exitError
+	}
+	return runCommand(stderr, "ssh", remote.SSHArgs(user, key, address, args.sshArgs))
+}
+
+// runCommand runs a program attached to the terminal, os/exec rather than
+// syscall.Exec so it also works on windows
+func runCommand(stderr *log.Logger, name string, args []string) int {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		stderr.Print(err)
+		return exitError
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		stderr.Print(err)
+		return exitError
+	}
+	return 0
+}
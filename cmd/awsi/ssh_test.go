@@ -0,0 +1,132 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"utils/aws/pkg/ec2"
+	"utils/aws/pkg/remote"
+)
+
+func TestParseSSHFlags(t *testing.T) {
+	var data = []struct {
+		args            []string
+		expectedSearch  []string
+		expectedSSHArgs []string
+		expectedUser    string
+		expectedAddress []string
+		expectedErr     string
+	}{
+		{[]string{"web"}, []string{"web"}, nil, "", nil, ""},
+		{[]string{"-l", "ubuntu", "web", "prod"}, []string{"web", "prod"}, nil, "ubuntu", nil, ""},
+		{[]string{"web", "--", "-A", "uptime"}, []string{"web"}, []string{"-A", "uptime"}, "", nil, ""},
+		{[]string{"-address", "public-dns,private", "web"}, []string{"web"}, nil, "", []string{"public-dns", "private"}, ""},
+		{[]string{"-address", "elastic", "web"}, nil, nil, "", nil, `invalid value "elastic" for flag -address`},
+		{[]string{}, nil, nil, "", nil, "a search term is needed"},
+		{[]string{"--", "-A"}, nil, nil, "", nil, "a search term is needed"},
+	}
+	for _, d := range data {
+		t.Run(strings.Join(d.args, " "), func(t *testing.T) {
+			args, output, err := parseSSHFlags("awsi", d.args)
+			if d.expectedErr != "" {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if !strings.Contains(output, d.expectedErr) {
+					t.Errorf("output got %q, want it to contain %q", output, d.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(args.search, d.expectedSearch) {
+				t.Errorf("search got %v, want %v", args.search, d.expectedSearch)
+			}
+			if !reflect.DeepEqual(args.sshArgs, d.expectedSSHArgs) {
+				t.Errorf("sshArgs got %v, want %v", args.sshArgs, d.expectedSSHArgs)
+			}
+			if args.user != d.expectedUser {
+				t.Errorf("user got %q, want %q", args.user, d.expectedUser)
+			}
+			if !reflect.DeepEqual(args.address, d.expectedAddress) {
+				t.Errorf("address got %v, want %v", args.address, d.expectedAddress)
+			}
+		})
+	}
+}
+
+func TestChooseInstance(t *testing.T) {
+	views := []ec2.View{
+		{Name: "web-1", InstanceID: "i-1", PrivateIP: "10.0.0.1"},
+		{Name: "web-2", InstanceID: "i-2", PrivateIP: "10.0.0.2"},
+	}
+	var data = []struct {
+		name        string
+		views       []ec2.View
+		input       string
+		expectedID  string
+		expectedErr string
+	}{
+		{"single", views[:1], "", "i-1", ""},
+		{"first", views, "1\n", "i-1", ""},
+		{"second", views, " 2 \n", "i-2", ""},
+		{"no newline", views, "2", "i-2", ""},
+		{"out of range", views, "3\n", "", `invalid choice "3"`},
+		{"not a number", views, "web\n", "", `invalid choice "web"`},
+		{"no answer", views, "", "", "no instance chosen"},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			var out strings.Builder
+			view, err := chooseInstance(strings.NewReader(d.input), &out, d.views)
+			if d.expectedErr != "" {
+				if err == nil || err.Error() != d.expectedErr {
+					t.Errorf("err got %v, want %s", err, d.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if view.InstanceID != d.expectedID {
+				t.Errorf("InstanceID got %s, want %s", view.InstanceID, d.expectedID)
+			}
+			if len(d.views) > 1 && !strings.Contains(out.String(), "2) web-2") {
+				t.Errorf("prompt got %q, want numbered instances", out.String())
+			}
+		})
+	}
+}
+
+func TestSSHLogin(t *testing.T) {
+	config := remote.Config{
+		Address: []string{"public"},
+		User:    "ec2-user",
+		Key:     "/keys/default.pem",
+	}
+	var data = []struct {
+		name            string
+		args            sshArguments
+		expectedUser    string
+		expectedKey     string
+		expectedAddress []string
+	}{
+		{"config", sshArguments{}, "ec2-user", "/keys/default.pem", []string{"public"}},
+		{"flags", sshArguments{user: "root", key: "/keys/root.pem", address: []string{"private"}}, "root", "/keys/root.pem", []string{"private"}},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			user, key, kinds := sshLogin(d.args, config, ec2.View{})
+			if user != d.expectedUser {
+				t.Errorf("user got %s, want %s", user, d.expectedUser)
+			}
+			if key != d.expectedKey {
+				t.Errorf("key got %s, want %s", key, d.expectedKey)
+			}
+			if !reflect.DeepEqual(kinds, d.expectedAddress) {
+				t.Errorf("kinds got %v, want %v", kinds, d.expectedAddress)
+			}
+		})
+	}
+}
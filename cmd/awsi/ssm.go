@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"utils/aws/pkg/remote"
+)
+
+type ssmArguments struct {
+	profile string
+	region  string
+	forward *remote.Forward
+	search  []string
+}
+
+func parseForward(forward **remote.Forward) func(string) error {
+	return func(s string) error {
+		parsed, err := remote.ParseForward(s)
+		if err != nil {
+			return err
+		}
+		*forward = &parsed
+		return nil
+	}
+}
+
+func parseSSMFlags(cmdName string, args []string) (ssmArguments, string, error) {
+	var a ssmArguments
+	var buf bytes.Buffer
+	flags := flag.NewFlagSet(cmdName, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage of %s ssm: [OPTIONS...] search-term...\n\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "Find a running instance and start an ssm session with it, asking which one when several match.\nNeeds the %s from the aws cli.\n\n", remote.SessionManagerPlugin)
+		flags.PrintDefaults()
+	}
+	flags.SetOutput(&buf)
+	flags.StringVar(&a.profile, "profile", "", "aws config profile to search")
+	flags.StringVar(&a.region, "region", "", "region to search (default region from aws config)")
+	flags.Func("forward", "forward a local port instead of starting a shell, local-port:host:remote-port with host as seen from the instance, e.g. 5432:localhost:5432", parseForward(&a.forward))
+	err := flags.Parse(args)
+	if err != nil {
+		return a, buf.String(), err
+	}
+	a.search = flags.Args()
+	if len(a.search) == 0 {
+		err = errors.New("a search term is needed to find the instance")
+		fmt.Fprintln(&buf, err)
+		return a, buf.String(), err
+	}
+	return a, buf.String(), nil
+}
+
+// runSSM returns the exit code of the session manager plugin, or of awsi when
+// the session could not be started
+func runSSM(cmdArgs []string, stderr *log.Logger) int {
+	args, output, err := parseSSMFlags(os.Args[0], cmdArgs)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(os.Stderr, output)
+		return 0
+	}
+	if err != nil {
+		stderr.Print(strings.TrimSpace(output))
+		return exitError
+	}
+	// look for the plugin before starting a session it would not connect to
+	_, err = exec.LookPath(remote.SessionManagerPlugin)
+	if err != nil {
+		stderr.Printf("%s is needed for ssm sessions: %v", remote.SessionManagerPlugin, err)
+		return exitError
+	}
+	ctx := context.Background()
+	cfg, err := loadRemoteConfig(ctx, args.profile, args.region)
+	if err != nil {
+		stderr.Print(err)
+		return exitError
+	}
+	view, err := findInstance(ctx, cfg, args.search)
+	if err != nil {
+		message, code := describeError(err)
+		stderr.Print(message)
+		return code
+	}
+	session, err := remote.StartSession(ctx, cfg, remote.SessionInput(view.InstanceID, args.forward))
+	if err != nil {
+		stderr.Print(err)
+		return exitError
+	}
+	pluginArgs, err := session.PluginArgs(args.profile)
+	if err != nil {
+		stderr.Print(err)
+		return exitError
+	}
+	if args.forward != nil {
+		fmt.Fprintf(os.Stderr, "forwarding localhost:%d to %s:%d on %s\n", args.forward.LocalPort, args.forward.Host, args.forward.RemotePort, view.InstanceID)
+	}
+	// the plugin handles ctrl-c itself, as with the aws cli
+	signal.Ignore(os.Interrupt)
+	return runCommand(stderr, remote.SessionManagerPlugin, pluginArgs)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"utils/aws/pkg/remote"
+)
+
+func TestParseSSMFlags(t *testing.T) {
+	var data = []struct {
+		args            []string
+		expectedSearch  []string
+		expectedForward *remote.Forward
+		expectedErr     string
+	}{
+		{[]string{"web"}, []string{"web"}, nil, ""},
+		{[]string{"-forward", "5432:localhost:5432", "db"}, []string{"db"}, &remote.Forward{LocalPort: 5432, Host: "localhost", RemotePort: 5432}, ""},
+		{[]string{"-forward", "8080:80", "web", "prod"}, []string{"web", "prod"}, &remote.Forward{LocalPort: 8080, Host: "localhost", RemotePort: 80}, ""},
+		{[]string{"-forward", "5432", "db"}, nil, nil, `invalid value "5432" for flag -forward`},
+		{[]string{}, nil, nil, "a search term is needed"},
+	}
+	for _, d := range data {
+		t.Run(strings.Join(d.args, " "), func(t *testing.T) {
+			args, output, err := parseSSMFlags("awsi", d.args)
+			if d.expectedErr != "" {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if !strings.Contains(output, d.expectedErr) {
+					t.Errorf("output got %q, want it to contain %q", output, d.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(args.search, d.expectedSearch) {
+				t.Errorf("search got %v, want %v", args.search, d.expectedSearch)
+			}
+			if !reflect.DeepEqual(args.forward, d.expectedForward) {
+				t.Errorf("forward got %+v, want %+v", args.forward, d.expectedForward)
+			}
+		})
+	}
+}
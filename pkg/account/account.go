@@ -0,0 +1,152 @@
+package account
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"utils/aws/pkg/parallel"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+const (
+	identifyWorkers = 8
+	roleSessionName = "awsi"
+)
+
+type Account struct {
+	Profile string
+	RoleARN string
+	ID      string
+	Alias   string
+	Config  aws.Config
+}
+
+func (a Account) Label() string {
+	switch {
+	case a.ID == "" && a.Profile != "":
+		return a.Profile
+	case a.ID == "":
+		return a.RoleARN
+	case a.Alias == "":
+		return a.ID
+	}
+	return fmt.Sprintf("%s (%s)", a.ID, a.Alias)
+}
+
+type Role struct {
+	ARN   string
+	Alias string
+}
+
+func FromProfiles(ctx context.Context, profiles []string) ([]Account, error) {
+	accounts := make([]Account, 0, len(profiles))
+	for _, profile := range profiles {
+		cfg, err := config.LoadDefaultConfig(ctx, config.WithSharedConfigProfile(profile))
+		if err != nil {
+			return nil, fmt.Errorf("profile %s: %w", profile, err)
+		}
+		accounts = append(accounts, Account{Profile: profile, Config: cfg})
+	}
+	return accounts, nil
+}
+
+func FromRoles(cfg aws.Config, roles []Role) []Account {
+	client := sts.NewFromConfig(cfg)
+	accounts := make([]Account, 0, len(roles))
+	for _, role := range roles {
+		roleCfg := cfg.Copy()
+		roleCfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(client, role.ARN, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = roleSessionName
+		}))
+		accounts = append(accounts, Account{RoleARN: role.ARN, Alias: role.Alias, Config: roleCfg})
+	}
+	return accounts
+}
+
+func ParseRole(s string) (Role, error) {
+	parsed, err := arn.Parse(s)
+	if err != nil {
+		return Role{}, fmt.Errorf("invalid role arn %q: %w", s, err)
+	}
+	if parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, "role/") {
+		return Role{}, fmt.Errorf("invalid role arn %q: expected arn:<partition>:iam::<account>:role/<name>", s)
+	}
+	return Role{ARN: s}, nil
+}
+
+func ReadAccountsFile(r io.Reader) ([]Role, error) {
+	roles := make([]Role, 0, 10)
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: expected role arn and optional alias, got %d fields", lineNumber, len(fields))
+		}
+		role, err := ParseRole(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		if len(fields) == 2 {
+			role.Alias = fields[1]
+		}
+		roles = append(roles, role)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+type callerIdentifier interface {
+	GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+type aliasLister interface {
+	ListAccountAliases(ctx context.Context, params *iam.ListAccountAliasesInput, optFns ...func(*iam.Options)) (*iam.ListAccountAliasesOutput, error)
+}
+
+func IdentifyAll(ctx context.Context, accounts []Account) []error {
+	errs := make([]error, len(accounts))
+	parallel.ForEach(len(accounts), identifyWorkers, func(i int) {
+		cfg := accounts[i].Config
+		errs[i] = identify(ctx, &accounts[i], sts.NewFromConfig(cfg), iam.NewFromConfig(cfg))
+	})
+	return errs
+}
+
+// the iam alias is a nicety, accounts without one or roles without
+// iam:ListAccountAliases fall back to the profile name
+func identify(ctx context.Context, account *Account, identifier callerIdentifier, lister aliasLister) error {
+	identity, err := identifier.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return err
+	}
+	account.ID = *identity.Account
+	if account.Alias != "" {
+		return nil
+	}
+	aliases, err := lister.ListAccountAliases(ctx, &iam.ListAccountAliasesInput{})
+	if err == nil && len(aliases.AccountAliases) > 0 {
+		account.Alias = aliases.AccountAliases[0]
+	} else {
+		account.Alias = account.Profile
+	}
+	return nil
+}
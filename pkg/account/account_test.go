@@ -0,0 +1,164 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+func TestReadAccountsFile(t *testing.T) {
+	var data = []struct {
+		testName      string
+		content       string
+		expectedRoles []Role
+		expectedErr   string
+	}{
+		{"empty", "", []Role{}, ""},
+		{"comments and blank lines", "# accounts\n\n   \n", []Role{}, ""},
+		{"roles with and without alias",
+			"arn:aws:iam::111111111111:role/ReadOnly prod # production\narn:aws:iam::222222222222:role/ReadOnly\n",
+			[]Role{
+				{ARN: "arn:aws:iam::111111111111:role/ReadOnly", Alias: "prod"},
+				{ARN: "arn:aws:iam::222222222222:role/ReadOnly"},
+			}, ""},
+		{"not an arn", "# header\n111111111111\n", nil, "line 2: invalid role arn"},
+		{"not iam", "arn:aws:s3:::bucket\n", nil, "line 1: invalid role arn"},
+		{"not a role", "arn:aws:iam::111111111111:user/alice\n", nil, "line 1: invalid role arn"},
+		{"china partition", "arn:aws-cn:iam::111111111111:role/ReadOnly\n",
+			[]Role{{ARN: "arn:aws-cn:iam::111111111111:role/ReadOnly"}}, ""},
+		{"too many fields", "arn:aws:iam::111111111111:role/ReadOnly prod extra\n", nil, "line 1: expected role arn and optional alias"},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			roles, err := ReadAccountsFile(strings.NewReader(d.content))
+			if d.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), d.expectedErr) {
+					t.Fatalf("err got %v, want it to contain %q", err, d.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(roles, d.expectedRoles) {
+				t.Errorf("roles got %+v, want %+v", roles, d.expectedRoles)
+			}
+		})
+	}
+}
+
+func TestFromRoles(t *testing.T) {
+	roles := []Role{
+		{ARN: "arn:aws:iam::111111111111:role/ReadOnly", Alias: "prod"},
+		{ARN: "arn:aws:iam::222222222222:role/ReadOnly"},
+	}
+	cfg := aws.Config{Region: "eu-west-1"}
+	accounts := FromRoles(cfg, roles)
+	if len(accounts) != len(roles) {
+		t.Fatalf("number of accounts got %d, want %d", len(accounts), len(roles))
+	}
+	for i, account := range accounts {
+		if account.RoleARN != roles[i].ARN || account.Alias != roles[i].Alias {
+			t.Errorf("account got %+v, want role %+v", account, roles[i])
+		}
+		if account.Config.Region != cfg.Region {
+			t.Errorf("region got %s, want %s", account.Config.Region, cfg.Region)
+		}
+		if account.Config.Credentials == nil {
+			t.Error("expected assume role credentials")
+		}
+	}
+}
+
+type callerIdentifierMock struct {
+	account string
+	err     error
+}
+
+func (cim callerIdentifierMock) GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	if cim.err != nil {
+		return nil, cim.err
+	}
+	return &sts.GetCallerIdentityOutput{Account: &cim.account}, nil
+}
+
+type aliasListerMock struct {
+	aliases []string
+	err     error
+	called  bool
+}
+
+func (alm *aliasListerMock) ListAccountAliases(ctx context.Context, params *iam.ListAccountAliasesInput, optFns ...func(*iam.Options)) (*iam.ListAccountAliasesOutput, error) {
+	alm.called = true
+	if alm.err != nil {
+		return nil, alm.err
+	}
+	return &iam.ListAccountAliasesOutput{AccountAliases: alm.aliases}, nil
+}
+
+func TestIdentify(t *testing.T) {
+	var data = []struct {
+		testName       string
+		account        Account
+		aliases        []string
+		aliasErr       error
+		expectedAlias  string
+		expectedLookup bool
+	}{
+		{"iam alias", Account{Profile: "dev"}, []string{"acme-dev"}, nil, "acme-dev", true},
+		{"no iam alias uses profile", Account{Profile: "dev"}, []string{}, nil, "dev", true},
+		{"alias denied uses profile", Account{Profile: "dev"}, nil, errors.New("denied"), "dev", true},
+		{"configured alias wins", Account{Alias: "prod"}, []string{"acme-prod"}, nil, "prod", false},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			account := d.account
+			lister := aliasListerMock{aliases: d.aliases, err: d.aliasErr}
+			err := identify(nil, &account, callerIdentifierMock{account: "123456789012"}, &lister)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if account.ID != "123456789012" {
+				t.Errorf("ID got %s, want 123456789012", account.ID)
+			}
+			if account.Alias != d.expectedAlias {
+				t.Errorf("Alias got %s, want %s", account.Alias, d.expectedAlias)
+			}
+			if lister.called != d.expectedLookup {
+				t.Errorf("alias lookup got %v, want %v", lister.called, d.expectedLookup)
+			}
+		})
+	}
+}
+
+func TestIdentifyError(t *testing.T) {
+	stsErr := errors.New("expired")
+	account := Account{Profile: "dev"}
+	err := identify(nil, &account, callerIdentifierMock{err: stsErr}, &aliasListerMock{})
+	if !errors.Is(err, stsErr) {
+		t.Errorf("err got %v, want %v", err, stsErr)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	var data = []struct {
+		account  Account
+		expected string
+	}{
+		{Account{ID: "123456789012", Alias: "prod"}, "123456789012 (prod)"},
+		{Account{ID: "123456789012"}, "123456789012"},
+		{Account{Profile: "dev"}, "dev"},
+		{Account{RoleARN: "arn:aws:iam::123456789012:role/ReadOnly", Alias: "prod"}, "arn:aws:iam::123456789012:role/ReadOnly"},
+	}
+	for _, d := range data {
+		if d.account.Label() != d.expected {
+			t.Errorf("Label got %q, want %q", d.account.Label(), d.expected)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package duration
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+var units = map[byte]time.Duration{
+	'w': 7 * 24 * time.Hour,
+	'd': 24 * time.Hour,
+	'h': time.Hour,
+	'm': time.Minute,
+	's': time.Second,
+}
+
+// Parse parses whole numbers of weeks, days, hours, minutes and seconds such
+// as 90d, 2h or 3d4h
+func Parse(s string) (time.Duration, error) {
+	var total time.Duration
+	i := 0
+	for i < len(s) {
+		start := i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		if i == start || i == len(s) {
+			return 0, fmt.Errorf("invalid duration %q, use e.g. 90d, 2h or 3d4h", s)
+		}
+		unit, ok := units[s[i]]
+		if !ok {
+			return 0, fmt.Errorf("invalid duration %q, use e.g. 90d, 2h or 3d4h", s)
+		}
+		n, err := strconv.Atoi(s[start:i])
+		if err != nil {
+			return 0, err
+		}
+		total += time.Duration(n) * unit
+		i++
+	}
+	if len(s) == 0 {
+		return 0, errors.New("empty duration")
+	}
+	return total, nil
+}
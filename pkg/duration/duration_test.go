@@ -0,0 +1,36 @@
+package duration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	var data = []struct {
+		s        string
+		expected time.Duration
+		err      bool
+	}{
+		{"2h", 2 * time.Hour, false},
+		{"90d", 90 * 24 * time.Hour, false},
+		{"3d4h", 76 * time.Hour, false},
+		{"1w2d", 9 * 24 * time.Hour, false},
+		{"45m30s", 45*time.Minute + 30*time.Second, false},
+		{"", 0, true},
+		{"2", 0, true},
+		{"h", 0, true},
+		{"2y", 0, true},
+		{"1.5h", 0, true},
+	}
+	for _, d := range data {
+		t.Run(d.s, func(t *testing.T) {
+			duration, err := Parse(d.s)
+			if (err != nil) != d.err {
+				t.Fatalf("err got %v, want error %v", err, d.err)
+			}
+			if duration != d.expected {
+				t.Errorf("duration got %v, want %v", duration, d.expected)
+			}
+		})
+	}
+}
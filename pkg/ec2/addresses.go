@@ -0,0 +1,89 @@
+package ec2
+
+import (
+	"net"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+var addressFilterNames = []struct {
+	kind       searchKind
+	filterName string
+}{
+	// matches secondary addresses on every interface, not only the primary private ip
+	{privateIPSearch, "network-interface.addresses.private-ip-address"},
+	{publicIPSearch, "ip-address"},
+	{ipv6Search, "network-interface.ipv6-addresses.ipv6-address"},
+	{privateDNSSearch, "private-dns-name"},
+	{publicDNSSearch, "dns-name"},
+}
+
+func addressFilters(search []string) []types.Filter {
+	var filters = make([]types.Filter, 0, 1)
+	for _, f := range addressFilterNames {
+		values := findAll(search, isKind(f.kind))
+		if len(values) > 0 {
+			filters = append(filters, filter(f.filterName, values))
+		}
+	}
+	return filters
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, cidr, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return cidr
+}
+
+func parseCIDRs(args []string) []*net.IPNet {
+	var cidrs = make([]*net.IPNet, 0, len(args))
+	for _, arg := range args {
+		cidrs = append(cidrs, mustParseCIDR(arg))
+	}
+	return cidrs
+}
+
+func instanceAddresses(instance types.Instance) []net.IP {
+	var addresses = make([]string, 0, 4)
+	if instance.PrivateIpAddress != nil {
+		addresses = append(addresses, *instance.PrivateIpAddress)
+	}
+	if instance.PublicIpAddress != nil {
+		addresses = append(addresses, *instance.PublicIpAddress)
+	}
+	for _, networkInterface := range instance.NetworkInterfaces {
+		for _, private := range networkInterface.PrivateIpAddresses {
+			if private.PrivateIpAddress != nil {
+				addresses = append(addresses, *private.PrivateIpAddress)
+			}
+			if private.Association != nil && private.Association.PublicIp != nil {
+				addresses = append(addresses, *private.Association.PublicIp)
+			}
+		}
+		for _, ipv6 := range networkInterface.Ipv6Addresses {
+			if ipv6.Ipv6Address != nil {
+				addresses = append(addresses, *ipv6.Ipv6Address)
+			}
+		}
+	}
+	var ips = make([]net.IP, 0, len(addresses))
+	for _, address := range addresses {
+		if ip := net.ParseIP(address); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+func inAnyCIDR(ips []net.IP, cidrs []*net.IPNet) bool {
+	for _, ip := range ips {
+		for _, cidr := range cidrs {
+			if cidr.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
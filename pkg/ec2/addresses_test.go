@@ -0,0 +1,65 @@
+package ec2
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestInstanceAddresses(t *testing.T) {
+	instance := types.Instance{
+		PrivateIpAddress: mkStrRef("10.0.0.1"),
+		PublicIpAddress:  mkStrRef("54.1.2.3"),
+		NetworkInterfaces: []types.InstanceNetworkInterface{
+			{
+				PrivateIpAddresses: []types.InstancePrivateIpAddress{
+					{PrivateIpAddress: mkStrRef("10.0.0.1"), Association: &types.InstanceNetworkInterfaceAssociation{PublicIp: mkStrRef("54.1.2.3")}},
+					{PrivateIpAddress: mkStrRef("10.0.0.2")},
+				},
+				Ipv6Addresses: []types.InstanceIpv6Address{{Ipv6Address: mkStrRef("2001:db8::1")}},
+			},
+			{PrivateIpAddresses: []types.InstancePrivateIpAddress{{PrivateIpAddress: mkStrRef("10.1.0.9")}}},
+		},
+	}
+	result := instanceAddresses(instance)
+	expected := []string{"10.0.0.1", "54.1.2.3", "10.0.0.1", "54.1.2.3", "10.0.0.2", "2001:db8::1", "10.1.0.9"}
+	resultStrings := make([]string, len(result))
+	for i, ip := range result {
+		resultStrings[i] = ip.String()
+	}
+	if !reflect.DeepEqual(resultStrings, expected) {
+		t.Errorf("got %v, want %v", resultStrings, expected)
+	}
+}
+
+func TestInstanceAddressesEmpty(t *testing.T) {
+	if result := instanceAddresses(types.Instance{}); len(result) != 0 {
+		t.Errorf("got %v, want no addresses", result)
+	}
+}
+
+func TestInAnyCIDR(t *testing.T) {
+	cidrs := parseCIDRs([]string{"10.2.0.0/16", "2001:db8::/32"})
+	var data = []struct {
+		ips      []string
+		expected bool
+	}{
+		{[]string{}, false},
+		{[]string{"10.2.3.4"}, true},
+		{[]string{"10.3.3.4"}, false},
+		{[]string{"10.3.3.4", "10.2.0.1"}, true},
+		{[]string{"2001:db8:1::5"}, true},
+		{[]string{"2001:db9::5"}, false},
+	}
+	for _, d := range data {
+		ips := make([]net.IP, len(d.ips))
+		for i, ip := range d.ips {
+			ips[i] = net.ParseIP(ip)
+		}
+		if result := inAnyCIDR(ips, cidrs); result != d.expected {
+			t.Errorf("inAnyCIDR(%v) got %v, want %v", d.ips, result, d.expected)
+		}
+	}
+}
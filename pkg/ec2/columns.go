@@ -0,0 +1,206 @@
+package ec2
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+const launchTimeFormat = "2006-01-02T15:04:05"
+
+type Column struct {
+	Heading string
+	Value   func(instance types.Instance) string
+	// columns that describe where an instance was found rather than the instance itself
+	result func(result Result) string
+}
+
+func (c Column) value(result Result, instance types.Instance) string {
+	if c.result != nil {
+		return c.result(result)
+	}
+	return c.Value(instance)
+}
+
+var DefaultColumns = []string{"name", "id", "privateIp", "az", "state", "type", "launched", "imageId"}
+
+// IdentifierColumns are cut last when the table is too wide, a shortened id
+// or address is no use for copying
+var IdentifierColumns = []string{"id", "privateIp", "publicIp", "imageId"}
+
+var columnPresets = map[string][]string{
+	"default": DefaultColumns,
+	"network": {"name", "id", "privateIp", "publicIp", "vpc", "subnet", "securityGroups"},
+}
+
+var accountColumn = Column{Heading: "account", result: func(result Result) string {
+	return orDash(result.Account)
+}}
+
+var regionColumn = Column{Heading: "region", result: func(result Result) string {
+	return orDash(result.Region)
+}}
+
+var columns = map[string]Column{
+	"account": accountColumn,
+	"region":  regionColumn,
+	"name": instanceColumn("name", func(instance types.Instance) string {
+		return instanceName(instance)
+	}),
+	"id": instanceColumn("id", func(instance types.Instance) string {
+		return stringValue(instance.InstanceId)
+	}),
+	"privateIp": instanceColumn("privateIp", func(instance types.Instance) string {
+		return stringValue(instance.PrivateIpAddress)
+	}),
+	"publicIp": instanceColumn("publicIp", func(instance types.Instance) string {
+		return stringValue(instance.PublicIpAddress)
+	}),
+	"privateDns": instanceColumn("privateDns", func(instance types.Instance) string {
+		return stringValue(instance.PrivateDnsName)
+	}),
+	"publicDns": instanceColumn("publicDns", func(instance types.Instance) string {
+		return stringValue(instance.PublicDnsName)
+	}),
+	"az": instanceColumn("az", func(instance types.Instance) string {
+		if instance.Placement == nil {
+			return ""
+		}
+		return stringValue(instance.Placement.AvailabilityZone)
+	}),
+	"state": instanceColumn("state", func(instance types.Instance) string {
+		if instance.State == nil {
+			return ""
+		}
+		return string(instance.State.Name)
+	}),
+	"type": instanceColumn("type", func(instance types.Instance) string {
+		return string(instance.InstanceType)
+	}),
+	"launched": instanceColumn("launched", func(instance types.Instance) string {
+		if instance.LaunchTime == nil {
+			return ""
+		}
+		return instance.LaunchTime.Format(launchTimeFormat)
+	}),
+	"age": instanceColumn("age", func(instance types.Instance) string {
+		if instance.LaunchTime == nil {
+			return ""
+		}
+		return FormatAge(now().Sub(*instance.LaunchTime))
+	}),
+	"imageId": instanceColumn("imageId", func(instance types.Instance) string {
+		return stringValue(instance.ImageId)
+	}),
+	"vpc": instanceColumn("vpc", func(instance types.Instance) string {
+		return stringValue(instance.VpcId)
+	}),
+	"subnet": instanceColumn("subnet", func(instance types.Instance) string {
+		return stringValue(instance.SubnetId)
+	}),
+	"keyName": instanceColumn("keyName", func(instance types.Instance) string {
+		return stringValue(instance.KeyName)
+	}),
+	"platform": instanceColumn("platform", func(instance types.Instance) string {
+		return stringValue(instance.PlatformDetails)
+	}),
+	"arch": instanceColumn("arch", func(instance types.Instance) string {
+		return string(instance.Architecture)
+	}),
+	// InstanceLifecycle is only set for spot and scheduled instances
+	"lifecycle": instanceColumn("lifecycle", func(instance types.Instance) string {
+		if instance.InstanceLifecycle == "" {
+			return "on-demand"
+		}
+		return string(instance.InstanceLifecycle)
+	}),
+	"iamProfile": instanceColumn("iamProfile", func(instance types.Instance) string {
+		if instance.IamInstanceProfile == nil {
+			return ""
+		}
+		arn := stringValue(instance.IamInstanceProfile.Arn)
+		return arn[strings.LastIndex(arn, "/")+1:]
+	}),
+	"securityGroups": instanceColumn("securityGroups", func(instance types.Instance) string {
+		names := make([]string, 0, len(instance.SecurityGroups))
+		for _, group := range instance.SecurityGroups {
+			names = append(names, stringValue(group.GroupName))
+		}
+		return strings.Join(names, ",")
+	}),
+	"tenancy": instanceColumn("tenancy", func(instance types.Instance) string {
+		if instance.Placement == nil {
+			return ""
+		}
+		return string(instance.Placement.Tenancy)
+	}),
+	"rootDevice": instanceColumn("rootDevice", func(instance types.Instance) string {
+		return string(instance.RootDeviceType)
+	}),
+	"monitoring": instanceColumn("monitoring", func(instance types.Instance) string {
+		if instance.Monitoring == nil {
+			return ""
+		}
+		return string(instance.Monitoring.State)
+	}),
+}
+
+// empty values are shown as - so every cell has something in it
+func instanceColumn(heading string, value func(instance types.Instance) string) Column {
+	return Column{Heading: heading, Value: func(instance types.Instance) string {
+		return orDash(value(instance))
+	}}
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func TagColumn(key string) Column {
+	return instanceColumn(key, func(instance types.Instance) string {
+		return stringValue(tagValueByKey(instance.Tags, key))
+	})
+}
+
+func ScoreColumn(terms []string) Column {
+	return Column{Heading: "score", Value: func(instance types.Instance) string {
+		return strconv.Itoa(FuzzyScore(terms, instanceName(instance)))
+	}}
+}
+
+func ColumnNames() []string {
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupColumns resolves column names, presets such as default and tag:<key> columns
+func LookupColumns(names []string) ([]Column, error) {
+	var result = make([]Column, 0, len(names))
+	for _, name := range names {
+		if preset, ok := columnPresets[name]; ok {
+			presetColumns, _ := LookupColumns(preset)
+			result = append(result, presetColumns...)
+			continue
+		}
+		if strings.HasPrefix(name, "tag:") && len(name) > len("tag:") {
+			result = append(result, TagColumn(strings.TrimPrefix(name, "tag:")))
+			continue
+		}
+		column, ok := columns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q, choose from %s, tag:<key> or a preset (default, network)", name, strings.Join(ColumnNames(), ", "))
+		}
+		result = append(result, column)
+	}
+	return result, nil
+}
@@ -0,0 +1,112 @@
+package ec2
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestLookupColumns(t *testing.T) {
+	var data = []struct {
+		names    []string
+		headings []string
+		err      string
+	}{
+		{[]string{"name", "publicIp"}, []string{"name", "publicIp"}, ""},
+		{[]string{"default"}, DefaultColumns, ""},
+		{[]string{"id", "network"}, []string{"id", "name", "id", "privateIp", "publicIp", "vpc", "subnet", "securityGroups"}, ""},
+		{[]string{"tag:Owner", "region"}, []string{"Owner", "region"}, ""},
+		{[]string{"tag:"}, nil, "unknown column \"tag:\""},
+		{[]string{"publicip"}, nil, "unknown column \"publicip\""},
+	}
+	for _, d := range data {
+		t.Run(strings.Join(d.names, ","), func(t *testing.T) {
+			columns, err := LookupColumns(d.names)
+			if d.err != "" {
+				if err == nil || !strings.Contains(err.Error(), d.err) {
+					t.Fatalf("err got %v, want %q", err, d.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			headings := make([]string, len(columns))
+			for i, column := range columns {
+				headings[i] = column.Heading
+			}
+			if !reflect.DeepEqual(headings, d.headings) {
+				t.Errorf("headings got %v, want %v", headings, d.headings)
+			}
+		})
+	}
+}
+
+func TestColumnValues(t *testing.T) {
+	lTime, _ := time.Parse(time.RFC3339, "2021-09-26T19:21:42Z")
+	now = func() time.Time { return lTime.Add(76 * time.Hour) }
+	defer func() { now = time.Now }()
+	instance := createInstance(mkStrRef("web"), "i-1", mkStrRef("10.0.0.1"), "us-east-1a", types.InstanceState{Name: types.InstanceStateNameRunning},
+		types.InstanceTypeT3Micro, lTime, "ami-1", []types.Tag{{Key: mkStrRef("Owner"), Value: mkStrRef("alice")}})
+	instance.VpcId = mkStrRef("vpc-1")
+	instance.SecurityGroups = []types.GroupIdentifier{{GroupName: mkStrRef("web")}, {GroupName: mkStrRef("ssh")}}
+	instance.IamInstanceProfile = &types.IamInstanceProfile{Arn: mkStrRef("arn:aws:iam::111111111111:instance-profile/web-role")}
+	instance.InstanceLifecycle = types.InstanceLifecycleTypeSpot
+	var data = []struct {
+		name     string
+		expected string
+	}{
+		{"name", "web"},
+		{"publicIp", "-"},
+		{"vpc", "vpc-1"},
+		{"subnet", "-"},
+		{"securityGroups", "web,ssh"},
+		{"iamProfile", "web-role"},
+		{"lifecycle", "spot"},
+		{"launched", "2021-09-26T19:21:42"},
+		{"age", "3d4h"},
+		{"tag:Owner", "alice"},
+		{"tag:Team", "-"},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			columns, err := LookupColumns([]string{d.name})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if value := columns[0].Value(instance); value != d.expected {
+				t.Errorf("value got %q, want %q", value, d.expected)
+			}
+		})
+	}
+}
+
+func TestNewTableLocationColumns(t *testing.T) {
+	lTime, _ := time.Parse(time.RFC3339, "2021-09-26T19:21:42Z")
+	outputFor := func(id string) *ec2.DescribeInstancesOutput {
+		return &ec2.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: []types.Instance{
+			createInstance(mkStrRef("web"), id, nil, "us-east-1a", types.InstanceState{Name: types.InstanceStateNameRunning}, types.InstanceTypeT3Micro, lTime, "ami-1", []types.Tag{}),
+		}}}}
+	}
+	results := []Result{
+		{Account: "111111111111", Region: "us-east-1", Output: outputFor("i-1")},
+		{Account: "222222222222", Region: "eu-west-1", Output: outputFor("i-2")},
+	}
+	columns, _ := LookupColumns([]string{"id", "region"})
+	ec2Table, err := NewTable(results, columns, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedHeader := []string{"account", "id", "region"}
+	if !reflect.DeepEqual(ec2Table.Header, expectedHeader) {
+		t.Errorf("header got %v, want %v", ec2Table.Header, expectedHeader)
+	}
+	expectedRows := [][]string{{"111111111111", "i-1", "us-east-1"}, {"222222222222", "i-2", "eu-west-1"}}
+	if !reflect.DeepEqual(ec2Table.Rows, expectedRows) {
+		t.Errorf("rows got %v, want %v", ec2Table.Rows, expectedRows)
+	}
+}
@@ -10,40 +10,79 @@ import (
 )
 
 func Default(ec2Output *ec2.DescribeInstancesOutput, withTags bool) (*table.FixedWidthFont, error) {
-	var instances = table.New([]string{"name", "id", "privateIp", "az", "state", "type", "launched", "imageId"})
-	for _, reservation := range ec2Output.Reservations {
+	defaultColumns, _ := LookupColumns(DefaultColumns)
+	return NewTable([]Result{{Output: ec2Output}}, defaultColumns, withTags)
+}
+
+// NewTable adds account and region columns when the results span several of
+// them, unless the columns already include them
+func NewTable(results []Result, columns []Column, withTags bool) (*table.FixedWidthFont, error) {
+	var location = make([]Column, 0, 2)
+	if distinct(results, accountColumn.result) > 1 && !hasColumn(columns, accountColumn.Heading) {
+		location = append(location, accountColumn)
+	}
+	if distinct(results, regionColumn.result) > 1 && !hasColumn(columns, regionColumn.Heading) {
+		location = append(location, regionColumn)
+	}
+	columns = append(location, columns...)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Heading
+	}
+	var instances = table.New(header)
+	for _, result := range results {
+		err := addInstances(&instances, columns, result, withTags)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &instances, nil
+}
+
+func hasColumn(columns []Column, heading string) bool {
+	for _, column := range columns {
+		if column.Heading == heading {
+			return true
+		}
+	}
+	return false
+}
+
+func distinct(results []Result, key func(Result) string) int {
+	seen := make(map[string]bool)
+	for _, result := range results {
+		if value := key(result); value != "-" {
+			seen[value] = true
+		}
+	}
+	return len(seen)
+}
+
+func addInstances(instances *table.FixedWidthFont, columns []Column, result Result, withTags bool) error {
+	if result.Err != nil || result.Output == nil {
+		return nil
+	}
+	for _, reservation := range result.Output.Reservations {
 		for _, instance := range reservation.Instances {
-			launchTime := *instance.LaunchTime
-			var privateIP = "-"
-			if instance.PrivateIpAddress != nil {
-				privateIP = *instance.PrivateIpAddress
-			}
-			nameTag := tagValueByKey(instance.Tags, "Name")
 			tags := []table.Tag{}
 			if withTags {
 				tags = tableTags(instance.Tags)
 			}
-			var name = "-"
-			if nameTag != nil {
-				name = *nameTag
+			row := make([]string, len(columns))
+			for i, column := range columns {
+				row[i] = column.value(result, instance)
 			}
-			err := instances.AddRow([]string{
-				name,
-				*instance.InstanceId,
-				privateIP,
-				//*instance.NetworkInterfaces,
-				*instance.Placement.AvailabilityZone,
-				string(instance.State.Name),
-				string(instance.InstanceType),
-				launchTime.Format("2006-01-02T15:04:05"),
-				*instance.ImageId,
-			}, tags)
+			err := instances.AddRow(row, tags)
 			if err != nil {
-				return nil, err
+				return err
 			}
 		}
 	}
-	return &instances, nil
+	return nil
+}
+
+func instanceName(instance types.Instance) string {
+	return stringValue(tagValueByKey(instance.Tags, "Name"))
 }
 
 func tagValueByKey(tags []types.Tag, key string) *string {
@@ -1,6 +1,7 @@
 package ec2
 
 import (
+	"errors"
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -112,3 +113,110 @@ func createInstance(name *string, id string, privateIp *string, az string, state
 		Tags:              tags,
 	}
 }
+
+func TestNewTable(t *testing.T) {
+	lTime, _ := time.Parse(time.RFC3339, "2021-09-26T19:21:42Z")
+	running := types.InstanceState{Name: types.InstanceStateNameRunning}
+	outputFor := func(id string, az string) *ec2.DescribeInstancesOutput {
+		return &ec2.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: []types.Instance{
+			createInstance(mkStrRef("web"), id, mkStrRef("10.0.0.1"), az, running, types.InstanceTypeT3Micro, lTime, "ami-1", []types.Tag{}),
+		}}}}
+	}
+	var data = []struct {
+		testName       string
+		results        []Result
+		expectedHeader []string
+		expectedRows   [][]string
+	}{
+		{"single region has no region column",
+			[]Result{{Region: "us-east-1", Output: outputFor("i-1", "us-east-1a")}},
+			DefaultColumns,
+			[][]string{{"web", "i-1", "10.0.0.1", "us-east-1a", "running", "t3.micro", "2021-09-26T19:21:42", "ami-1"}}},
+		{"multiple regions",
+			[]Result{
+				{Region: "us-east-1", Output: outputFor("i-1", "us-east-1a")},
+				{Region: "eu-west-1", Output: outputFor("i-2", "eu-west-1b")},
+			},
+			append([]string{"region"}, DefaultColumns...),
+			[][]string{
+				{"us-east-1", "web", "i-1", "10.0.0.1", "us-east-1a", "running", "t3.micro", "2021-09-26T19:21:42", "ami-1"},
+				{"eu-west-1", "web", "i-2", "10.0.0.1", "eu-west-1b", "running", "t3.micro", "2021-09-26T19:21:42", "ami-1"},
+			}},
+		{"multiple accounts in one region",
+			[]Result{
+				{Account: "111111111111 (prod)", Region: "us-east-1", Output: outputFor("i-1", "us-east-1a")},
+				{Account: "222222222222 (dev)", Region: "us-east-1", Output: outputFor("i-2", "us-east-1b")},
+			},
+			append([]string{"account"}, DefaultColumns...),
+			[][]string{
+				{"111111111111 (prod)", "web", "i-1", "10.0.0.1", "us-east-1a", "running", "t3.micro", "2021-09-26T19:21:42", "ami-1"},
+				{"222222222222 (dev)", "web", "i-2", "10.0.0.1", "us-east-1b", "running", "t3.micro", "2021-09-26T19:21:42", "ami-1"},
+			}},
+		{"multiple accounts and regions",
+			[]Result{
+				{Account: "111111111111", Region: "us-east-1", Output: outputFor("i-1", "us-east-1a")},
+				{Account: "222222222222", Region: "eu-west-1", Output: outputFor("i-2", "eu-west-1b")},
+			},
+			append([]string{"account", "region"}, DefaultColumns...),
+			[][]string{
+				{"111111111111", "us-east-1", "web", "i-1", "10.0.0.1", "us-east-1a", "running", "t3.micro", "2021-09-26T19:21:42", "ami-1"},
+				{"222222222222", "eu-west-1", "web", "i-2", "10.0.0.1", "eu-west-1b", "running", "t3.micro", "2021-09-26T19:21:42", "ami-1"},
+			}},
+		{"failed region is skipped",
+			[]Result{
+				{Region: "us-east-1", Err: errors.New("access denied")},
+				{Region: "eu-west-1", Output: outputFor("i-2", "eu-west-1b")},
+			},
+			append([]string{"region"}, DefaultColumns...),
+			[][]string{
+				{"eu-west-1", "web", "i-2", "10.0.0.1", "eu-west-1b", "running", "t3.micro", "2021-09-26T19:21:42", "ami-1"},
+			}},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			table, err := NewTable(d.results, defaultColumns(t), false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(table.Header, d.expectedHeader) {
+				t.Errorf("header got %v, want %v", table.Header, d.expectedHeader)
+			}
+			if !reflect.DeepEqual(table.Rows, d.expectedRows) {
+				t.Errorf("rows got %v, want %v", table.Rows, d.expectedRows)
+			}
+		})
+	}
+}
+
+func TestNewTableExtraColumns(t *testing.T) {
+	lTime, _ := time.Parse(time.RFC3339, "2021-09-26T19:21:42Z")
+	running := types.InstanceState{Name: types.InstanceStateNameRunning}
+	results := []Result{{Region: "us-east-1", Output: &ec2.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: []types.Instance{
+		createInstance(mkStrRef("web-1"), "i-1", nil, "us-east-1a", running, types.InstanceTypeT3Micro, lTime, "ami-1", []types.Tag{}),
+		createInstance(mkStrRef("my-web"), "i-2", nil, "us-east-1a", running, types.InstanceTypeT3Micro, lTime, "ami-2", []types.Tag{}),
+	}}}}}}
+	columns := append(defaultColumns(t), ScoreColumn([]string{"web"}), Column{Heading: "ami", Value: func(instance types.Instance) string {
+		return *instance.ImageId
+	}})
+	ec2Table, err := NewTable(results, columns, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedHeader := append(append([]string{}, DefaultColumns...), "score", "ami")
+	if !reflect.DeepEqual(ec2Table.Header, expectedHeader) {
+		t.Errorf("header got %v, want %v", ec2Table.Header, expectedHeader)
+	}
+	for i, row := range ec2Table.Rows {
+		if row[len(row)-2] == "0" || row[len(row)-1] == "" {
+			t.Errorf("row %d extra columns got %v", i, row[len(row)-2:])
+		}
+	}
+}
+
+func defaultColumns(t *testing.T) []Column {
+	columns, err := LookupColumns(DefaultColumns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return columns
+}
@@ -0,0 +1,59 @@
+package ec2
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/smithy-go"
+)
+
+var (
+	ErrAccessDenied       = errors.New("access denied")
+	ErrExpiredCredentials = errors.New("expired credentials")
+	ErrThrottled          = errors.New("request throttled")
+	ErrInvalidInstanceID  = errors.New("invalid instance id")
+)
+
+var errorKindsByCode = map[string]error{
+	"AccessDenied":                ErrAccessDenied,
+	"AccessDeniedException":       ErrAccessDenied,
+	"AuthFailure":                 ErrAccessDenied,
+	"UnauthorizedOperation":       ErrAccessDenied,
+	"ExpiredToken":                ErrExpiredCredentials,
+	"ExpiredTokenException":       ErrExpiredCredentials,
+	"RequestExpired":              ErrExpiredCredentials,
+	"RequestLimitExceeded":        ErrThrottled,
+	"Throttling":                  ErrThrottled,
+	"ThrottlingException":         ErrThrottled,
+	"InvalidInstanceID.Malformed": ErrInvalidInstanceID,
+	"InvalidInstanceID.NotFound":  ErrInvalidInstanceID,
+}
+
+type Error struct {
+	Kind error
+	Err  error
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%v: %v", e.Kind, e.Err)
+}
+
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
+func (e Error) Is(target error) bool {
+	return e.Kind == target
+}
+
+func ClassifyError(err error) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	kind, ok := errorKindsByCode[apiErr.ErrorCode()]
+	if !ok {
+		return err
+	}
+	return Error{Kind: kind, Err: err}
+}
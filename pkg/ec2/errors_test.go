@@ -0,0 +1,50 @@
+package ec2
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestClassifyError(t *testing.T) {
+	var data = []struct {
+		err          error
+		expectedKind error
+	}{
+		{&smithy.GenericAPIError{Code: "UnauthorizedOperation"}, ErrAccessDenied},
+		{&smithy.GenericAPIError{Code: "AuthFailure"}, ErrAccessDenied},
+		{&smithy.GenericAPIError{Code: "ExpiredToken"}, ErrExpiredCredentials},
+		{&smithy.GenericAPIError{Code: "RequestExpired"}, ErrExpiredCredentials},
+		{&smithy.GenericAPIError{Code: "RequestLimitExceeded"}, ErrThrottled},
+		{&smithy.GenericAPIError{Code: "InvalidInstanceID.Malformed"}, ErrInvalidInstanceID},
+		{&smithy.GenericAPIError{Code: "InvalidInstanceID.NotFound"}, ErrInvalidInstanceID},
+		{&smithy.OperationError{ServiceID: "EC2", OperationName: "DescribeInstances",
+			Err: &smithy.GenericAPIError{Code: "UnauthorizedOperation"}}, ErrAccessDenied},
+		{&smithy.GenericAPIError{Code: "InternalError"}, nil},
+		{errors.New("not an api error"), nil},
+	}
+	for _, d := range data {
+		t.Run(d.err.Error(), func(t *testing.T) {
+			result := ClassifyError(d.err)
+			if !errors.Is(result, d.err) {
+				t.Errorf("expected %v to wrap %v", result, d.err)
+			}
+			for _, kind := range []error{ErrAccessDenied, ErrExpiredCredentials, ErrThrottled, ErrInvalidInstanceID} {
+				expected := kind == d.expectedKind
+				if errors.Is(result, kind) != expected {
+					t.Errorf("errors.Is(%v, %v) got %v, want %v", result, kind, !expected, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	err := Error{Kind: ErrThrottled, Err: fmt.Errorf("slow down")}
+	expected := "request throttled: slow down"
+	if err.Error() != expected {
+		t.Errorf("message got %q, want %q", err.Error(), expected)
+	}
+}
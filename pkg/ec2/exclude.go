@@ -0,0 +1,101 @@
+package ec2
+
+import (
+	"net"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// instance attributes that key=value exclusions can match as well as tags, e.g. state=terminated
+var excludableFields = map[string]func(types.Instance) string{
+	"state": func(instance types.Instance) string {
+		if instance.State == nil {
+			return ""
+		}
+		return string(instance.State.Name)
+	},
+	"type": func(instance types.Instance) string {
+		return string(instance.InstanceType)
+	},
+	"az": func(instance types.Instance) string {
+		if instance.Placement == nil {
+			return ""
+		}
+		return stringValue(instance.Placement.AvailabilityZone)
+	},
+}
+
+func Exclude(ec2Output *ec2.DescribeInstancesOutput, terms []string) *ec2.DescribeInstancesOutput {
+	if len(terms) == 0 {
+		return ec2Output
+	}
+	result := *ec2Output
+	result.Reservations = filterInstances(ec2Output.Reservations, notExcluded(terms))
+	return &result
+}
+
+func notExcluded(terms []string) func(types.Instance) bool {
+	return func(instance types.Instance) bool {
+		for _, term := range terms {
+			if matchesTerm(instance, term) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func matchesTerm(instance types.Instance, term string) bool {
+	switch kindOf(term) {
+	case instanceIDSearch:
+		return wildcardMatch(term, stringValue(instance.InstanceId))
+	case amiIDSearch:
+		return wildcardMatch(term, stringValue(instance.ImageId))
+	case tagSearch:
+		return matchesTagTerm(instance, term)
+	case privateIPSearch, publicIPSearch, ipv6Search:
+		ip := net.ParseIP(term)
+		for _, address := range instanceAddresses(instance) {
+			if address.Equal(ip) {
+				return true
+			}
+		}
+		return false
+	case cidrSearch:
+		return inAnyCIDR(instanceAddresses(instance), parseCIDRs([]string{term}))
+	case privateDNSSearch:
+		return wildcardMatch(term, stringValue(instance.PrivateDnsName))
+	case publicDNSSearch:
+		return wildcardMatch(term, stringValue(instance.PublicDnsName))
+	case excludedSearch:
+		return false
+	}
+	name := tagValueByKey(instance.Tags, "Name")
+	return name != nil && wildcardMatch(term, *name)
+}
+
+func matchesTagTerm(instance types.Instance, term string) bool {
+	i := strings.Index(term, "=")
+	key, value := term[:i], term[i+1:]
+	if field, ok := excludableFields[key]; ok && value != "" && wildcardMatch(value, field(instance)) {
+		return true
+	}
+	for _, tag := range instance.Tags {
+		if !wildcardMatch(key, stringValue(tag.Key)) {
+			continue
+		}
+		if value == "" || wildcardMatch(value, stringValue(tag.Value)) {
+			return true
+		}
+	}
+	return false
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
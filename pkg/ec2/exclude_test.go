@@ -0,0 +1,99 @@
+package ec2
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func createExcludeTestOutput() *ec2.DescribeInstancesOutput {
+	instance := func(id string, name string, ami string, state types.InstanceStateName, privateIP string, tags ...types.Tag) types.Instance {
+		return types.Instance{
+			InstanceId:       mkStrRef(id),
+			ImageId:          mkStrRef(ami),
+			PrivateIpAddress: mkStrRef(privateIP),
+			State:            &types.InstanceState{Name: state},
+			Tags:             append(tags, types.Tag{Key: mkStrRef("Name"), Value: mkStrRef(name)}),
+		}
+	}
+	return &ec2.DescribeInstancesOutput{Reservations: []types.Reservation{
+		{Instances: []types.Instance{
+			instance("i-1", "web-1", "ami-1", types.InstanceStateNameRunning, "10.0.0.1", types.Tag{Key: mkStrRef("env"), Value: mkStrRef("prod")}),
+			instance("i-2", "web-canary-1", "ami-2", types.InstanceStateNameRunning, "10.0.1.1", types.Tag{Key: mkStrRef("env"), Value: mkStrRef("prod")}),
+		}},
+		{Instances: []types.Instance{
+			instance("i-3", "web-2", "ami-1", types.InstanceStateNameTerminated, "10.0.0.2", types.Tag{Key: mkStrRef("env"), Value: mkStrRef("staging")}),
+		}},
+	}}
+}
+
+func instanceIDs(output *ec2.DescribeInstancesOutput) []string {
+	ids := []string{}
+	for _, reservation := range output.Reservations {
+		for _, instance := range reservation.Instances {
+			ids = append(ids, *instance.InstanceId)
+		}
+	}
+	return ids
+}
+
+func TestExclude(t *testing.T) {
+	var data = []struct {
+		testName    string
+		terms       []string
+		expectedIDs []string
+	}{
+		{"nothing excluded", []string{}, []string{"i-1", "i-2", "i-3"}},
+		{"name", []string{"web-canary*"}, []string{"i-1", "i-3"}},
+		{"instance id", []string{"i-2"}, []string{"i-1", "i-3"}},
+		{"ami", []string{"ami-1"}, []string{"i-2"}},
+		{"tag value", []string{"env=staging"}, []string{"i-1", "i-2"}},
+		{"tag presence", []string{"env="}, []string{}},
+		{"state", []string{"state=terminated"}, []string{"i-1", "i-2"}},
+		{"private ip", []string{"10.0.0.1"}, []string{"i-2", "i-3"}},
+		{"cidr", []string{"10.0.0.0/24"}, []string{"i-2"}},
+		{"several terms", []string{"web-canary*", "state=terminated"}, []string{"i-1"}},
+		{"no match", []string{"db-*"}, []string{"i-1", "i-2", "i-3"}},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			output := createExcludeTestOutput()
+			result := Exclude(output, d.terms)
+			if ids := instanceIDs(result); !reflect.DeepEqual(ids, d.expectedIDs) {
+				t.Errorf("instances got %v, want %v", ids, d.expectedIDs)
+			}
+			if ids := instanceIDs(output); len(ids) != 3 {
+				t.Errorf("input was modified, instances now %v", ids)
+			}
+		})
+	}
+}
+
+func TestFindExcludedArgs(t *testing.T) {
+	search := []string{"web-*", "!web-canary*", "!env=staging", "i-123", "!i-456"}
+	expected := []string{"web-canary*", "env=staging", "i-456"}
+	if result := FindExcludedArgs(search); !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %v, want %v", result, expected)
+	}
+	if result := FindNameSearchArgs(search); !reflect.DeepEqual(result, []string{"web-*"}) {
+		t.Errorf("names got %v, want [web-*]", result)
+	}
+	if result := FindInstanceIDArgs(search); !reflect.DeepEqual(result, []string{"i-123"}) {
+		t.Errorf("instance ids got %v, want [i-123]", result)
+	}
+}
+
+func TestGetInstancesExcludeBeforeLimit(t *testing.T) {
+	mockInstanceFinder := instanceFinderMock{output: createExcludeTestOutput()}
+
+	result, err := getInstances(nil, &mockInstanceFinder, []string{"web-*"}, Options{Limit: 1, Exclude: []string{"web-1"}})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids := instanceIDs(result); !reflect.DeepEqual(ids, []string{"i-2"}) {
+		t.Errorf("instances got %v, want [i-2]", ids)
+	}
+}
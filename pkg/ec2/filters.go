@@ -0,0 +1,195 @@
+package ec2
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+var knownFilters = []string{
+	"affinity",
+	"architecture",
+	"availability-zone",
+	"block-device-mapping.attach-time",
+	"block-device-mapping.delete-on-termination",
+	"block-device-mapping.device-name",
+	"block-device-mapping.status",
+	"block-device-mapping.volume-id",
+	"capacity-reservation-id",
+	"client-token",
+	"dns-name",
+	"hibernation-options.configured",
+	"host-id",
+	"hypervisor",
+	"iam-instance-profile.arn",
+	"image-id",
+	"instance-id",
+	"instance-lifecycle",
+	"instance-state-code",
+	"instance-state-name",
+	"instance-type",
+	"instance.group-id",
+	"instance.group-name",
+	"ip-address",
+	"kernel-id",
+	"key-name",
+	"launch-index",
+	"launch-time",
+	"metadata-options.http-endpoint",
+	"metadata-options.http-put-response-hop-limit",
+	"metadata-options.http-tokens",
+	"monitoring-state",
+	"network-interface.addresses.association.ip-owner-id",
+	"network-interface.addresses.association.public-ip",
+	"network-interface.addresses.primary",
+	"network-interface.addresses.private-ip-address",
+	"network-interface.association.allocation-id",
+	"network-interface.association.association-id",
+	"network-interface.association.ip-owner-id",
+	"network-interface.association.public-ip",
+	"network-interface.attachment.attach-time",
+	"network-interface.attachment.attachment-id",
+	"network-interface.attachment.delete-on-termination",
+	"network-interface.attachment.device-index",
+	"network-interface.attachment.instance-id",
+	"network-interface.attachment.instance-owner-id",
+	"network-interface.attachment.status",
+	"network-interface.availability-zone",
+	"network-interface.description",
+	"network-interface.group-id",
+	"network-interface.group-name",
+	"network-interface.ipv6-addresses.ipv6-address",
+	"network-interface.mac-address",
+	"network-interface.network-interface-id",
+	"network-interface.owner-id",
+	"network-interface.private-dns-name",
+	"network-interface.requester-id",
+	"network-interface.requester-managed",
+	"network-interface.source-dest-check",
+	"network-interface.status",
+	"network-interface.subnet-id",
+	"network-interface.vpc-id",
+	"outpost-arn",
+	"owner-id",
+	"placement-group-name",
+	"placement-partition-number",
+	"platform",
+	"private-dns-name",
+	"private-ip-address",
+	"product-code",
+	"product-code.type",
+	"ramdisk-id",
+	"reason",
+	"requester-id",
+	"reservation-id",
+	"root-device-name",
+	"root-device-type",
+	"source-dest-check",
+	"spot-instance-request-id",
+	"state-reason-code",
+	"state-reason-message",
+	"subnet-id",
+	"tag-key",
+	"tenancy",
+	"virtualization-type",
+	"vpc-id",
+}
+
+func ParseFilter(s string) (types.Filter, error) {
+	i := strings.Index(s, "=")
+	if i <= 0 || i == len(s)-1 {
+		return types.Filter{}, fmt.Errorf("expected name=value[,value...], got %q", s)
+	}
+	name, values := s[:i], strings.Split(s[i+1:], ",")
+	err := ValidateFilterName(name)
+	if err != nil {
+		return types.Filter{}, err
+	}
+	return filter(name, values), nil
+}
+
+func ValidateFilterName(name string) error {
+	if strings.HasPrefix(name, "tag:") && len(name) > len("tag:") {
+		return nil
+	}
+	i := sort.SearchStrings(knownFilters, name)
+	if i < len(knownFilters) && knownFilters[i] == name {
+		return nil
+	}
+	suggestions := closestFilters(name)
+	if len(suggestions) == 0 {
+		return fmt.Errorf("unknown filter %q", name)
+	}
+	return fmt.Errorf("unknown filter %q, did you mean %s?", name, quoteAlternatives(suggestions))
+}
+
+// filters that extend the name, e.g. instance-state to instance-state-name,
+// are better suggestions than ones that are a few edits away
+func closestFilters(name string) []string {
+	closest := make([]string, 0, 2)
+	for _, known := range knownFilters {
+		if !strings.HasPrefix(known, name) {
+			continue
+		}
+		if len(closest) > 0 && len(known) < len(closest[0]) {
+			closest = closest[:0]
+		}
+		if len(closest) == 0 || len(known) == len(closest[0]) {
+			closest = append(closest, known)
+		}
+	}
+	if len(closest) > 0 {
+		return closest
+	}
+	bestDistance := len(name)/3 + 3
+	for _, known := range knownFilters {
+		distance := editDistance(name, known)
+		if distance < bestDistance {
+			closest, bestDistance = closest[:0], distance
+		}
+		if distance == bestDistance {
+			closest = append(closest, known)
+		}
+	}
+	return closest
+}
+
+func quoteAlternatives(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
+
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, minInt(current[j-1]+1, previous[j-1]+cost))
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
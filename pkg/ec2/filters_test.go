@@ -0,0 +1,76 @@
+package ec2
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestKnownFiltersSorted(t *testing.T) {
+	if !sort.StringsAreSorted(knownFilters) {
+		t.Error("knownFilters must be sorted for binary search")
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	var data = []struct {
+		arg            string
+		expectedFilter types.Filter
+		expectedErr    string
+	}{
+		{"instance-state-name=running", filter("instance-state-name", []string{"running"}), ""},
+		{"instance-type=t3.micro,t3.small", filter("instance-type", []string{"t3.micro", "t3.small"}), ""},
+		{"iam-instance-profile.arn=arn:aws:iam::1:instance-profile/a=b", filter("iam-instance-profile.arn", []string{"arn:aws:iam::1:instance-profile/a=b"}), ""},
+		{"tag:team=payments", filter("tag:team", []string{"payments"}), ""},
+		{"vpc-id", types.Filter{}, "expected name=value[,value...]"},
+		{"vpc-id=", types.Filter{}, "expected name=value[,value...]"},
+		{"=vpc-123", types.Filter{}, "expected name=value[,value...]"},
+		{"instance-state=running", types.Filter{}, `unknown filter "instance-state", did you mean "instance-state-code" or "instance-state-name"?`},
+		{"vpcid=vpc-123", types.Filter{}, `unknown filter "vpcid", did you mean "vpc-id"?`},
+		{"subnet=subnet-123", types.Filter{}, `did you mean "subnet-id"?`},
+		{"instance-typ=t3.micro", types.Filter{}, `did you mean "instance-type"?`},
+		{"key=my-key", types.Filter{}, `did you mean "key-name"?`},
+		{"tag:=x", types.Filter{}, `unknown filter "tag:"`},
+		{"colour=blue", types.Filter{}, `unknown filter "colour"`},
+	}
+	for _, d := range data {
+		t.Run(d.arg, func(t *testing.T) {
+			result, err := ParseFilter(d.arg)
+			if d.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), d.expectedErr) {
+					t.Fatalf("err got %v, want it to contain %q", err, d.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, d.expectedFilter) {
+				t.Errorf("got %v, want %v", prettyFilters([]types.Filter{result}), prettyFilters([]types.Filter{d.expectedFilter}))
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	var data = []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"vpc-id", "vpc-id", 0},
+		{"vpcid", "vpc-id", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, d := range data {
+		if result := editDistance(d.a, d.b); result != d.expected {
+			t.Errorf("editDistance(%q, %q) got %d, want %d", d.a, d.b, result, d.expected)
+		}
+	}
+}
@@ -2,47 +2,290 @@ package ec2
 
 import (
 	"context"
-	"log"
-	"os"
+	"fmt"
+	"net"
 	"strings"
+	"time"
+	"utils/aws/pkg/duration"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
 
-func GetInstances(ctx context.Context, cfg aws.Config, search []string) *ec2.DescribeInstancesOutput {
-	return getInstances(ctx, ec2.NewFromConfig(cfg), search)
+type Options struct {
+	Limit     int
+	PageSize  int32
+	Filters   []types.Filter
+	NameMatch MatchMode
+	// instance-state-name values to return, all states when empty
+	States []string
+	// states to count in Result.Hidden, with one more request per region
+	HiddenStates []string
+	// search terms removing matching instances, as !term, applied before the limit
+	Exclude []string
+	// launch time bounds checked after fetching, ignored when zero
+	LaunchedAfter  time.Time
+	LaunchedBefore time.Time
+}
+
+var (
+	InstanceStates = []string{"pending", "running", "shutting-down", "terminated", "stopping", "stopped"}
+	DefaultStates  = []string{"pending", "running", "stopping", "stopped"}
+	HiddenStates   = []string{"shutting-down", "terminated"}
+)
+
+func ValidateStates(states []string) error {
+	for _, state := range states {
+		known := false
+		for _, instanceState := range InstanceStates {
+			known = known || state == instanceState
+		}
+		if !known {
+			return fmt.Errorf("unknown instance state %q, choose from %s", state, strings.Join(InstanceStates, ", "))
+		}
+	}
+	return nil
+}
+
+func GetInstances(ctx context.Context, cfg aws.Config, search []string, opts Options) (*ec2.DescribeInstancesOutput, error) {
+	return getInstances(ctx, ec2.NewFromConfig(cfg), search, opts)
 }
 
 type instanceFinder interface {
 	DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error)
 }
 
-func getInstances(ctx context.Context, finder instanceFinder, search []string) *ec2.DescribeInstancesOutput {
+func getInstances(ctx context.Context, finder instanceFinder, search []string, opts Options) (*ec2.DescribeInstancesOutput, error) {
+	input, keep, err := describeInput(search, opts)
+	if err != nil {
+		return nil, err
+	}
+	// MaxResults cannot be combined with InstanceIds in the same request
+	if opts.PageSize > 0 && len(input.InstanceIds) == 0 {
+		input.MaxResults = &opts.PageSize
+	}
+	var output *ec2.DescribeInstancesOutput
+	var nextToken *string
+	for {
+		params := input
+		params.NextToken = nextToken
+		page, err := finder.DescribeInstances(ctx, &params)
+		if err != nil {
+			return nil, ClassifyError(err)
+		}
+		page.Reservations = filterPage(page.Reservations, keep, opts)
+		if output == nil {
+			output = page
+		} else {
+			output.Reservations = append(output.Reservations, page.Reservations...)
+		}
+		nextToken = page.NextToken
+		if nextToken == nil || (opts.Limit > 0 && countInstances(output) >= opts.Limit) {
+			break
+		}
+	}
+	output.NextToken = nil
+	if opts.Limit > 0 {
+		output.Reservations = limitInstances(output.Reservations, opts.Limit)
+	}
+	return output, nil
+}
+
+// describeInput is the request for search, keep is what it cannot express
+func describeInput(search []string, opts Options) (input ec2.DescribeInstancesInput, keep func(types.Instance) bool, err error) {
 	filters := make([]types.Filter, 0, 2)
 	names := FindNameSearchArgs(search)
-	if len(names) > 0 {
+	if len(names) > 0 && opts.NameMatch == WildcardMatch {
 		filters = append(filters, filter("tag:Name", names))
 	}
+	filters = append(filters, tagFilters(FindTagSearchArgs(search))...)
 	amis := FindAmiIDArgs(search)
 	if len(amis) > 0 {
 		filters = append(filters, filter("image-id", amis))
 	}
-	input := ec2.DescribeInstancesInput{InstanceIds: FindInstanceIDArgs(search), Filters: filters}
-	output, err := finder.DescribeInstances(ctx, &input)
+	filters = append(filters, addressFilters(search)...)
+	filters = append(filters, opts.Filters...)
+	if len(opts.States) > 0 {
+		filters = append(filters, filter("instance-state-name", opts.States))
+	}
+	keep, err = clientSideFilter(search, opts)
 	if err != nil {
-		noTimestamp := 0
-		stderr := log.New(os.Stderr, "", noTimestamp)
-		stderr.Fatal(err)
+		return input, nil, err
+	}
+	return ec2.DescribeInstancesInput{InstanceIds: FindInstanceIDArgs(search), Filters: filters}, keep, nil
+}
+
+func filterPage(reservations []types.Reservation, keep func(types.Instance) bool, opts Options) []types.Reservation {
+	if keep != nil {
+		reservations = filterInstances(reservations, keep)
+	}
+	if len(opts.Exclude) > 0 {
+		reservations = filterInstances(reservations, notExcluded(opts.Exclude))
+	}
+	return reservations
+}
+
+// search terms ec2 filters cannot express, nil when there are none
+func clientSideFilter(search []string, opts Options) (func(types.Instance) bool, error) {
+	var predicates = make([]func(types.Instance) bool, 0, 2)
+	cidrs := parseCIDRs(FindCIDRArgs(search))
+	if len(cidrs) > 0 {
+		predicates = append(predicates, func(instance types.Instance) bool {
+			return inAnyCIDR(instanceAddresses(instance), cidrs)
+		})
+	}
+	names := FindNameSearchArgs(search)
+	if len(names) > 0 && opts.NameMatch != WildcardMatch {
+		matches, err := nameMatcher(opts.NameMatch, names)
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, func(instance types.Instance) bool {
+			return matches(instanceName(instance))
+		})
+	}
+	if !opts.LaunchedAfter.IsZero() || !opts.LaunchedBefore.IsZero() {
+		predicates = append(predicates, func(instance types.Instance) bool {
+			return launchedWithin(instance, opts.LaunchedAfter, opts.LaunchedBefore)
+		})
+	}
+	if len(predicates) == 0 {
+		return nil, nil
+	}
+	return func(instance types.Instance) bool {
+		for _, predicate := range predicates {
+			if !predicate(instance) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// the most DescribeInstances returns in one page
+const maxPageSize int32 = 1000
+
+// countInstancesInStates runs the same search for other states in a single
+// request, ignoring the limit. more is true when there were further pages
+// left uncounted.
+func countInstancesInStates(ctx context.Context, finder instanceFinder, search []string, opts Options, states []string) (count int, more bool, err error) {
+	opts.States = states
+	input, keep, err := describeInput(search, opts)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(input.InstanceIds) == 0 {
+		input.MaxResults = aws.Int32(maxPageSize)
+	}
+	page, err := finder.DescribeInstances(ctx, &input)
+	if err != nil {
+		return 0, false, ClassifyError(err)
+	}
+	page.Reservations = filterPage(page.Reservations, keep, opts)
+	return countInstances(page), page.NextToken != nil, nil
+}
+
+func launchedWithin(instance types.Instance, after time.Time, before time.Time) bool {
+	if instance.LaunchTime == nil {
+		return false
+	}
+	if !after.IsZero() && instance.LaunchTime.Before(after) {
+		return false
+	}
+	return before.IsZero() || instance.LaunchTime.Before(before)
+}
+
+// ParseTimeBound reads an RFC3339 time, or a duration such as 2h or 90d meaning that long before now
+func ParseTimeBound(s string, now time.Time) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err == nil {
+		return t, nil
+	}
+	age, err := duration.Parse(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, use an RFC3339 time or a duration such as 2h or 90d", s)
+	}
+	return now.Add(-age), nil
+}
+
+func filterInstances(reservations []types.Reservation, keep func(types.Instance) bool) []types.Reservation {
+	var result = make([]types.Reservation, 0, len(reservations))
+	for _, reservation := range reservations {
+		instances := make([]types.Instance, 0, len(reservation.Instances))
+		for _, instance := range reservation.Instances {
+			if keep(instance) {
+				instances = append(instances, instance)
+			}
+		}
+		if len(instances) > 0 {
+			reservation.Instances = instances
+			result = append(result, reservation)
+		}
+	}
+	return result
+}
+
+func countInstances(output *ec2.DescribeInstancesOutput) int {
+	count := 0
+	for _, reservation := range output.Reservations {
+		count += len(reservation.Instances)
+	}
+	return count
+}
+
+func limitInstances(reservations []types.Reservation, limit int) []types.Reservation {
+	var result = make([]types.Reservation, 0, len(reservations))
+	for _, reservation := range reservations {
+		if limit <= 0 {
+			break
+		}
+		if len(reservation.Instances) > limit {
+			reservation.Instances = reservation.Instances[:limit]
+		}
+		limit -= len(reservation.Instances)
+		result = append(result, reservation)
 	}
-	return output
+	return result
 }
 
 func filter(name string, values []string) types.Filter {
 	return types.Filter{Name: &name, Values: values}
 }
 
+// key=value terms for the same key are alternatives so share a filter, key= terms
+// only require the tag to be present. Values within a filter are or'd, so each
+// present key gets its own tag-key filter to be and'd like the other keys.
+func tagFilters(tagSearch []string) []types.Filter {
+	var keys = make([]string, 0, len(tagSearch))
+	var valuesByKey = make(map[string][]string)
+	var presentKeys = make([]string, 0, len(tagSearch))
+	var present = make(map[string]bool)
+	for _, arg := range tagSearch {
+		i := strings.Index(arg, "=")
+		key, value := arg[:i], arg[i+1:]
+		if value == "" {
+			if !present[key] {
+				presentKeys = append(presentKeys, key)
+				present[key] = true
+			}
+			continue
+		}
+		if _, ok := valuesByKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		valuesByKey[key] = append(valuesByKey[key], value)
+	}
+	var filters = make([]types.Filter, 0, len(keys)+len(presentKeys))
+	for _, key := range keys {
+		filters = append(filters, filter("tag:"+key, valuesByKey[key]))
+	}
+	for _, key := range presentKeys {
+		filters = append(filters, filter("tag-key", []string{key}))
+	}
+	return filters
+}
+
 func findAll(search []string, predicate func(string) bool) []string {
 	var result = make([]string, 0, len(search))
 	for _, arg := range search {
@@ -53,20 +296,111 @@ func findAll(search []string, predicate func(string) bool) []string {
 	return result
 }
 
+type searchKind int
+
+const (
+	nameSearch searchKind = iota
+	instanceIDSearch
+	amiIDSearch
+	tagSearch
+	privateIPSearch
+	publicIPSearch
+	ipv6Search
+	privateDNSSearch
+	publicDNSSearch
+	cidrSearch
+	excludedSearch
+)
+
+// 100.64.0.0/10 is not private in the rfc1918 sense but is commonly used for vpc secondary cidrs
+var sharedAddressSpace = mustParseCIDR("100.64.0.0/10")
+
+func kindOf(s string) searchKind {
+	if strings.HasPrefix(s, "!") {
+		return excludedSearch
+	}
+	if isTagSearch(s) {
+		return tagSearch
+	}
+	if _, _, err := net.ParseCIDR(s); err == nil {
+		return cidrSearch
+	}
+	if ip := net.ParseIP(s); ip != nil {
+		switch {
+		case ip.To4() == nil:
+			return ipv6Search
+		case ip.IsPrivate() || sharedAddressSpace.Contains(ip):
+			return privateIPSearch
+		}
+		return publicIPSearch
+	}
+	switch {
+	case strings.HasSuffix(s, ".ec2.internal") || strings.HasSuffix(s, ".compute.internal"):
+		return privateDNSSearch
+	case strings.HasPrefix(s, "ec2-") && strings.HasSuffix(s, ".amazonaws.com"):
+		return publicDNSSearch
+	case strings.HasPrefix(s, "i-"):
+		return instanceIDSearch
+	case strings.HasPrefix(s, "ami-"):
+		return amiIDSearch
+	}
+	return nameSearch
+}
+
+func isKind(kind searchKind) func(string) bool {
+	return func(s string) bool {
+		return kindOf(s) == kind
+	}
+}
+
+func isTagSearch(s string) bool {
+	return strings.Index(s, "=") > 0
+}
+
 func FindAmiIDArgs(search []string) []string {
-	return findAll(search, func(s string) bool {
-		return strings.HasPrefix(s, "ami-")
-	})
+	return findAll(search, isKind(amiIDSearch))
 }
 
 func FindInstanceIDArgs(search []string) []string {
-	return findAll(search, func(s string) bool {
-		return strings.HasPrefix(s, "i-")
-	})
+	return findAll(search, isKind(instanceIDSearch))
+}
+
+func FindTagSearchArgs(search []string) []string {
+	return findAll(search, isKind(tagSearch))
+}
+
+func FindPrivateIPArgs(search []string) []string {
+	return findAll(search, isKind(privateIPSearch))
+}
+
+func FindPublicIPArgs(search []string) []string {
+	return findAll(search, isKind(publicIPSearch))
+}
+
+func FindIPv6Args(search []string) []string {
+	return findAll(search, isKind(ipv6Search))
+}
+
+func FindPrivateDNSArgs(search []string) []string {
+	return findAll(search, isKind(privateDNSSearch))
+}
+
+func FindPublicDNSArgs(search []string) []string {
+	return findAll(search, isKind(publicDNSSearch))
+}
+
+func FindCIDRArgs(search []string) []string {
+	return findAll(search, isKind(cidrSearch))
+}
+
+func FindExcludedArgs(search []string) []string {
+	excluded := findAll(search, isKind(excludedSearch))
+	for i, arg := range excluded {
+		excluded[i] = strings.TrimPrefix(arg, "!")
+	}
+	return excluded
 }
 
 func FindNameSearchArgs(search []string) []string {
-	return findAll(search, func(s string) bool {
-		return !(strings.HasPrefix(s, "i-") || strings.HasPrefix(s, "ami-"))
-	})
+	return findAll(search, isKind(nameSearch))
 }
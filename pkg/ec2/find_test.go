@@ -2,13 +2,16 @@ package ec2
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
 )
 
 func TestFindAmiIdArgs(t *testing.T) {
@@ -53,6 +56,63 @@ func TestFindInstanceIdArgs(t *testing.T) {
 	}
 }
 
+func TestFindTagSearchArgs(t *testing.T) {
+	var data = []struct {
+		testName       string
+		search         []string
+		expectedResult []string
+	}{
+		{"no tags", []string{"a_name", "i-123", "ami-123"}, []string{}},
+		{"key value", []string{"env=prod"}, []string{"env=prod"}},
+		{"presence and wildcards", []string{"web", "team=", "env=prod*", "i-123"},
+			[]string{"team=", "env=prod*"}},
+		{"value containing =", []string{"query=a=b"}, []string{"query=a=b"}},
+		{"no key", []string{"=value"}, []string{}},
+		{"id like keys", []string{"i-owner=bob", "ami-source=base"}, []string{"i-owner=bob", "ami-source=base"}},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			result := FindTagSearchArgs(d.search)
+			if !reflect.DeepEqual(result, d.expectedResult) {
+				t.Errorf("got %+v, want %+v", result, d.expectedResult)
+			}
+		})
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	var data = []struct {
+		arg      string
+		expected searchKind
+	}{
+		{"web-*", nameSearch},
+		{"team/web", nameSearch},
+		{"i-0123456789abcdef0", instanceIDSearch},
+		{"ami-0123456789abcdef0", amiIDSearch},
+		{"env=prod", tagSearch},
+		{"10.2.3.4", privateIPSearch},
+		{"172.16.0.1", privateIPSearch},
+		{"192.168.1.1", privateIPSearch},
+		{"100.64.1.2", privateIPSearch},
+		{"54.1.2.3", publicIPSearch},
+		{"2001:db8::1", ipv6Search},
+		{"ip-10-2-3-4.ec2.internal", privateDNSSearch},
+		{"ip-10-2-3-4.eu-west-1.compute.internal", privateDNSSearch},
+		{"i-0123456789abcdef0.ec2.internal", privateDNSSearch},
+		{"ec2-54-1-2-3.compute-1.amazonaws.com", publicDNSSearch},
+		{"ec2-54-1-2-3.eu-west-1.compute.amazonaws.com", publicDNSSearch},
+		{"10.2.0.0/16", cidrSearch},
+		{"2001:db8::/32", cidrSearch},
+	}
+	for _, d := range data {
+		t.Run(d.arg, func(t *testing.T) {
+			if result := kindOf(d.arg); result != d.expected {
+				t.Errorf("got %v, want %v", result, d.expected)
+			}
+		})
+	}
+}
+
 func TestFindNameSearchArgs(t *testing.T) {
 	var data = []struct {
 		testName       string
@@ -63,6 +123,10 @@ func TestFindNameSearchArgs(t *testing.T) {
 		{"a name", []string{"instance_name"}, []string{"instance_name"}},
 		{"some names", []string{"i-123245", "something_else*", "a_name", "*mongo*"},
 			[]string{"something_else*", "a_name", "*mongo*"}},
+		{"tags are not names", []string{"env=prod", "web", "team="},
+			[]string{"web"}},
+		{"addresses are not names", []string{"10.0.0.1", "web", "ip-10-0-0-1.ec2.internal", "10.0.0.0/8"},
+			[]string{"web"}},
 	}
 	for _, d := range data {
 		t.Run(d.testName, func(t *testing.T) {
@@ -77,11 +141,23 @@ func TestFindNameSearchArgs(t *testing.T) {
 type instanceFinderMock struct {
 	expectedInput *ec2.DescribeInstancesInput
 	actualInput   *ec2.DescribeInstancesInput
+	actualInputs  []*ec2.DescribeInstancesInput
 	output        *ec2.DescribeInstancesOutput
+	pages         []*ec2.DescribeInstancesOutput
+	err           error
 }
 
 func (ifm *instanceFinderMock) DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
-	ifm.actualInput = params
+	if ifm.actualInput == nil {
+		ifm.actualInput = params
+	}
+	ifm.actualInputs = append(ifm.actualInputs, params)
+	if ifm.err != nil {
+		return nil, ifm.err
+	}
+	if len(ifm.pages) > 0 {
+		return ifm.pages[len(ifm.actualInputs)-1], nil
+	}
 	return ifm.output, nil
 }
 
@@ -128,12 +204,15 @@ func TestGetInstancesById(t *testing.T) {
 			expectedInput := ec2.DescribeInstancesInput{InstanceIds: d.instanceIds, Filters: []types.Filter{}}
 			mockInstanceFinder := instanceFinderMock{expectedInput: &expectedInput, output: &output}
 
-			result := getInstances(nil, &mockInstanceFinder, d.instanceIds)
+			result, err := getInstances(nil, &mockInstanceFinder, d.instanceIds, Options{})
 
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 			if result != &output {
 				t.Error("expected result to point to output returned from mock")
 			}
-			err := mockInstanceFinder.validate()
+			err = mockInstanceFinder.validate()
 			if err != nil {
 				t.Error(err)
 			}
@@ -160,12 +239,15 @@ func TestGetInstancesByImageId(t *testing.T) {
 			expectedInput := ec2.DescribeInstancesInput{InstanceIds: []string{}, Filters: filters}
 			mockInstanceFinder := instanceFinderMock{expectedInput: &expectedInput, output: &output}
 
-			result := getInstances(nil, &mockInstanceFinder, d.imageIds)
+			result, err := getInstances(nil, &mockInstanceFinder, d.imageIds, Options{})
 
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 			if result != &output {
 				t.Error("expected result to point to output returned from mock")
 			}
-			err := mockInstanceFinder.validate()
+			err = mockInstanceFinder.validate()
 			if err != nil {
 				t.Error(err)
 			}
@@ -192,15 +274,405 @@ func TestGetInstancesByName(t *testing.T) {
 			expectedInput := ec2.DescribeInstancesInput{InstanceIds: []string{}, Filters: filters}
 			mockInstanceFinder := instanceFinderMock{expectedInput: &expectedInput, output: &output}
 
-			result := getInstances(nil, &mockInstanceFinder, d.namePatterns)
+			result, err := getInstances(nil, &mockInstanceFinder, d.namePatterns, Options{})
 
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 			if result != &output {
 				t.Error("expected result to point to output returned from mock")
 			}
-			err := mockInstanceFinder.validate()
+			err = mockInstanceFinder.validate()
 			if err != nil {
 				t.Error(err)
 			}
 		})
 	}
 }
+
+func createPages(pageSizes ...int) []*ec2.DescribeInstancesOutput {
+	pages := make([]*ec2.DescribeInstancesOutput, len(pageSizes))
+	id := 0
+	for i, size := range pageSizes {
+		instances := make([]types.Instance, size)
+		for j := range instances {
+			instances[j] = types.Instance{InstanceId: mkStrRef(fmt.Sprintf("i-%d", id))}
+			id++
+		}
+		pages[i] = &ec2.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: instances}}}
+		if i+1 < len(pageSizes) {
+			pages[i].NextToken = mkStrRef(fmt.Sprintf("token-%d", i+1))
+		}
+	}
+	return pages
+}
+
+func TestGetInstancesPages(t *testing.T) {
+	var data = []struct {
+		testName          string
+		pageSizes         []int
+		opts              Options
+		expectedCalls     int
+		expectedInstances int
+	}{
+		{"single page", []int{3}, Options{}, 1, 3},
+		{"all pages", []int{3, 3, 1}, Options{}, 3, 7},
+		{"empty pages", []int{0, 2, 0}, Options{}, 3, 2},
+		{"limit within first page", []int{3, 3, 1}, Options{Limit: 2}, 1, 2},
+		{"limit across pages", []int{3, 3, 1}, Options{Limit: 4}, 2, 4},
+		{"limit above total", []int{3, 3, 1}, Options{Limit: 100}, 3, 7},
+		{"page size", []int{5, 5}, Options{PageSize: 5}, 2, 10},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			mockInstanceFinder := instanceFinderMock{pages: createPages(d.pageSizes...)}
+
+			result, err := getInstances(nil, &mockInstanceFinder, []string{}, d.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(mockInstanceFinder.actualInputs) != d.expectedCalls {
+				t.Errorf("DescribeInstances calls got %d, want %d", len(mockInstanceFinder.actualInputs), d.expectedCalls)
+			}
+			if countInstances(result) != d.expectedInstances {
+				t.Errorf("instances got %d, want %d", countInstances(result), d.expectedInstances)
+			}
+			if result.NextToken != nil {
+				t.Errorf("NextToken got %v, want nil", *result.NextToken)
+			}
+			for i, input := range mockInstanceFinder.actualInputs {
+				var expectedToken *string
+				if i > 0 {
+					expectedToken = mkStrRef(fmt.Sprintf("token-%d", i))
+				}
+				if !reflect.DeepEqual(input.NextToken, expectedToken) {
+					t.Errorf("call %d NextToken got %v, want %v", i, input.NextToken, expectedToken)
+				}
+				if d.opts.PageSize > 0 && (input.MaxResults == nil || *input.MaxResults != d.opts.PageSize) {
+					t.Errorf("call %d MaxResults got %v, want %d", i, input.MaxResults, d.opts.PageSize)
+				}
+			}
+		})
+	}
+}
+
+func TestGetInstancesPageSizeWithInstanceIds(t *testing.T) {
+	mockInstanceFinder := instanceFinderMock{output: &ec2.DescribeInstancesOutput{}}
+
+	_, err := getInstances(nil, &mockInstanceFinder, []string{"i-123"}, Options{PageSize: 50})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mockInstanceFinder.actualInput.MaxResults != nil {
+		t.Errorf("MaxResults got %d, want nil when searching by instance id", *mockInstanceFinder.actualInput.MaxResults)
+	}
+}
+
+func TestGetInstancesErrors(t *testing.T) {
+	var data = []struct {
+		testName     string
+		err          error
+		expectedKind error
+	}{
+		{"access denied", &smithy.GenericAPIError{Code: "UnauthorizedOperation"}, ErrAccessDenied},
+		{"expired credentials", &smithy.GenericAPIError{Code: "ExpiredToken"}, ErrExpiredCredentials},
+		{"throttled", &smithy.GenericAPIError{Code: "RequestLimitExceeded"}, ErrThrottled},
+		{"invalid instance id", &smithy.GenericAPIError{Code: "InvalidInstanceID.NotFound"}, ErrInvalidInstanceID},
+		{"other", errors.New("connection reset"), nil},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			mockInstanceFinder := instanceFinderMock{err: d.err}
+
+			result, err := getInstances(nil, &mockInstanceFinder, []string{"i-123"}, Options{})
+
+			if result != nil {
+				t.Errorf("result got %+v, want nil", result)
+			}
+			if !errors.Is(err, d.err) {
+				t.Errorf("err got %v, want it to wrap %v", err, d.err)
+			}
+			if d.expectedKind != nil && !errors.Is(err, d.expectedKind) {
+				t.Errorf("err got %v, want kind %v", err, d.expectedKind)
+			}
+		})
+	}
+}
+
+func TestGetInstancesByTag(t *testing.T) {
+	var data = []struct {
+		testName        string
+		search          []string
+		expectedFilters []types.Filter
+	}{
+		{"key value", []string{"env=prod"},
+			[]types.Filter{filter("tag:env", []string{"prod"})}},
+		{"same key is or", []string{"env=prod", "env=stag*"},
+			[]types.Filter{filter("tag:env", []string{"prod", "stag*"})}},
+		{"different keys are and", []string{"env=prod", "team=payments", "env=dr"},
+			[]types.Filter{filter("tag:env", []string{"prod", "dr"}), filter("tag:team", []string{"payments"})}},
+		{"presence", []string{"team="},
+			[]types.Filter{filter("tag-key", []string{"team"})}},
+		{"presence of several keys is and", []string{"team=", "owner=", "team="},
+			[]types.Filter{filter("tag-key", []string{"team"}), filter("tag-key", []string{"owner"})}},
+		{"combined with name and ami", []string{"web-*", "env=prod", "backup=", "ami-123"},
+			[]types.Filter{
+				filter("tag:Name", []string{"web-*"}),
+				filter("tag:env", []string{"prod"}),
+				filter("tag-key", []string{"backup"}),
+				filter("image-id", []string{"ami-123"}),
+			}},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			output := ec2.DescribeInstancesOutput{}
+			expectedInput := ec2.DescribeInstancesInput{InstanceIds: []string{}, Filters: d.expectedFilters}
+			mockInstanceFinder := instanceFinderMock{expectedInput: &expectedInput, output: &output}
+
+			_, err := getInstances(nil, &mockInstanceFinder, d.search, Options{})
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err = mockInstanceFinder.validate()
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestGetInstancesWithFilterOptions(t *testing.T) {
+	output := ec2.DescribeInstancesOutput{}
+	stateFilter := filter("instance-state-name", []string{"running", "stopped"})
+	vpcFilter := filter("vpc-id", []string{"vpc-123"})
+	expectedInput := ec2.DescribeInstancesInput{InstanceIds: []string{}, Filters: []types.Filter{
+		filter("tag:Name", []string{"web-*"}), stateFilter, vpcFilter,
+	}}
+	mockInstanceFinder := instanceFinderMock{expectedInput: &expectedInput, output: &output}
+
+	_, err := getInstances(nil, &mockInstanceFinder, []string{"web-*"}, Options{Filters: []types.Filter{stateFilter, vpcFilter}})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = mockInstanceFinder.validate()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetInstancesByAddress(t *testing.T) {
+	var data = []struct {
+		testName        string
+		search          []string
+		expectedFilters []types.Filter
+	}{
+		{"private ip", []string{"10.2.3.4", "10.2.3.5"},
+			[]types.Filter{filter("network-interface.addresses.private-ip-address", []string{"10.2.3.4", "10.2.3.5"})}},
+		{"public ip", []string{"54.1.2.3"},
+			[]types.Filter{filter("ip-address", []string{"54.1.2.3"})}},
+		{"ipv6", []string{"2001:db8::1"},
+			[]types.Filter{filter("network-interface.ipv6-addresses.ipv6-address", []string{"2001:db8::1"})}},
+		{"dns names", []string{"ip-10-2-3-4.ec2.internal", "ec2-54-1-2-3.compute-1.amazonaws.com"},
+			[]types.Filter{
+				filter("private-dns-name", []string{"ip-10-2-3-4.ec2.internal"}),
+				filter("dns-name", []string{"ec2-54-1-2-3.compute-1.amazonaws.com"}),
+			}},
+		{"cidr is client side", []string{"10.0.0.0/8"}, []types.Filter{}},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			output := ec2.DescribeInstancesOutput{}
+			expectedInput := ec2.DescribeInstancesInput{InstanceIds: []string{}, Filters: d.expectedFilters}
+			mockInstanceFinder := instanceFinderMock{expectedInput: &expectedInput, output: &output}
+
+			_, err := getInstances(nil, &mockInstanceFinder, d.search, Options{})
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err = mockInstanceFinder.validate()
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestGetInstancesByCIDR(t *testing.T) {
+	instance := func(id string, privateIP string) types.Instance {
+		return types.Instance{InstanceId: mkStrRef(id), PrivateIpAddress: mkStrRef(privateIP)}
+	}
+	pages := []*ec2.DescribeInstancesOutput{
+		{NextToken: mkStrRef("token-1"), Reservations: []types.Reservation{
+			{Instances: []types.Instance{instance("i-1", "10.1.0.5"), instance("i-2", "10.2.0.5")}},
+			{Instances: []types.Instance{instance("i-3", "192.168.0.1")}},
+		}},
+		{Reservations: []types.Reservation{
+			{Instances: []types.Instance{instance("i-4", "10.2.200.1")}},
+		}},
+	}
+	mockInstanceFinder := instanceFinderMock{pages: pages}
+
+	result, err := getInstances(nil, &mockInstanceFinder, []string{"10.2.0.0/16", "192.168.0.0/24"}, Options{Limit: 2})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ids := []string{}
+	for _, reservation := range result.Reservations {
+		for _, instance := range reservation.Instances {
+			ids = append(ids, *instance.InstanceId)
+		}
+	}
+	expected := []string{"i-2", "i-3"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("instances got %v, want %v", ids, expected)
+	}
+	if len(mockInstanceFinder.actualInputs) != 1 {
+		t.Errorf("DescribeInstances calls got %d, want 1 as the limit is reached after filtering the first page", len(mockInstanceFinder.actualInputs))
+	}
+}
+
+func TestGetInstancesClientSideNameMatch(t *testing.T) {
+	var data = []struct {
+		testName    string
+		search      []string
+		mode        MatchMode
+		expectedIDs []string
+	}{
+		{"regex", []string{"^WEB-[0-9]+$"}, RegexMatch, []string{"i-1", "i-3"}},
+		{"fuzzy", []string{"wbcn"}, FuzzyMatch, []string{"i-2"}},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			mockInstanceFinder := instanceFinderMock{output: createExcludeTestOutput()}
+
+			result, err := getInstances(nil, &mockInstanceFinder, d.search, Options{NameMatch: d.mode})
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, f := range mockInstanceFinder.actualInput.Filters {
+				if *f.Name == "tag:Name" {
+					t.Errorf("expected no tag:Name filter, got %v", f.Values)
+				}
+			}
+			if ids := instanceIDs(result); !reflect.DeepEqual(ids, d.expectedIDs) {
+				t.Errorf("instances got %v, want %v", ids, d.expectedIDs)
+			}
+		})
+	}
+}
+
+func TestGetInstancesInvalidRegex(t *testing.T) {
+	mockInstanceFinder := instanceFinderMock{output: &ec2.DescribeInstancesOutput{}}
+
+	_, err := getInstances(nil, &mockInstanceFinder, []string{"web-("}, Options{NameMatch: RegexMatch})
+
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if mockInstanceFinder.actualInput != nil {
+		t.Error("expected DescribeInstances not to be called")
+	}
+}
+
+func TestGetInstancesWithStates(t *testing.T) {
+	output := ec2.DescribeInstancesOutput{}
+	vpcFilter := types.Filter{Name: mkStrRef("vpc-id"), Values: []string{"vpc-123"}}
+	expectedInput := ec2.DescribeInstancesInput{InstanceIds: []string{}, Filters: []types.Filter{
+		{Name: mkStrRef("tag:Name"), Values: []string{"web-*"}},
+		vpcFilter,
+		{Name: mkStrRef("instance-state-name"), Values: []string{"running", "stopped"}},
+	}}
+	mockInstanceFinder := instanceFinderMock{expectedInput: &expectedInput, output: &output}
+	_, err := getInstances(nil, &mockInstanceFinder, []string{"web-*"}, Options{Filters: []types.Filter{vpcFilter}, States: []string{"running", "stopped"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = mockInstanceFinder.validate()
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestValidateStates(t *testing.T) {
+	err := ValidateStates([]string{"running", "shutting-down"})
+	if err != nil {
+		t.Errorf("err got %v, want nil", err)
+	}
+	err = ValidateStates([]string{"running", "stoped"})
+	if err == nil || !strings.Contains(err.Error(), "unknown instance state \"stoped\"") {
+		t.Errorf("err got %v, want unknown instance state", err)
+	}
+}
+
+func TestGetInstancesLaunched(t *testing.T) {
+	running := types.InstanceState{Name: types.InstanceStateNameRunning}
+	launchedAt := func(id string, launched string) types.Instance {
+		lTime, _ := time.Parse(time.RFC3339, launched)
+		return createInstance(mkStrRef("web"), id, nil, "us-east-1a", running, types.InstanceTypeT3Micro, lTime, "ami-1", []types.Tag{})
+	}
+	output := ec2.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: []types.Instance{
+		launchedAt("i-1", "2021-01-01T00:00:00Z"),
+		launchedAt("i-2", "2021-06-01T00:00:00Z"),
+		launchedAt("i-3", "2021-09-01T00:00:00Z"),
+		{InstanceId: mkStrRef("i-4")},
+	}}}}
+	mustParse := func(s string) time.Time {
+		parsed, _ := time.Parse(time.RFC3339, s)
+		return parsed
+	}
+	var data = []struct {
+		testName    string
+		opts        Options
+		expectedIDs []string
+	}{
+		{"after", Options{LaunchedAfter: mustParse("2021-05-01T00:00:00Z")}, []string{"i-2", "i-3"}},
+		{"before", Options{LaunchedBefore: mustParse("2021-05-01T00:00:00Z")}, []string{"i-1"}},
+		{"between", Options{LaunchedAfter: mustParse("2021-05-01T00:00:00Z"), LaunchedBefore: mustParse("2021-08-01T00:00:00Z")}, []string{"i-2"}},
+		{"no bounds", Options{}, []string{"i-1", "i-2", "i-3", "i-4"}},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			pageOutput := output
+			mockInstanceFinder := instanceFinderMock{output: &pageOutput}
+			result, err := getInstances(nil, &mockInstanceFinder, []string{}, d.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ids := instanceIDs(result); !reflect.DeepEqual(ids, d.expectedIDs) {
+				t.Errorf("instances got %v, want %v", ids, d.expectedIDs)
+			}
+		})
+	}
+}
+
+func TestParseTimeBound(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2021-09-26T19:00:00Z")
+	var data = []struct {
+		s        string
+		expected string
+		err      bool
+	}{
+		{"2021-09-01T00:00:00Z", "2021-09-01T00:00:00Z", false},
+		{"2h", "2021-09-26T17:00:00Z", false},
+		{"90d", "2021-06-28T19:00:00Z", false},
+		{"yesterday", "", true},
+	}
+	for _, d := range data {
+		t.Run(d.s, func(t *testing.T) {
+			bound, err := ParseTimeBound(d.s, now)
+			if (err != nil) != d.err {
+				t.Fatalf("err got %v, want error %v", err, d.err)
+			}
+			if !d.err && bound.Format(time.RFC3339) != d.expected {
+				t.Errorf("bound got %v, want %v", bound.Format(time.RFC3339), d.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package ec2
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// wildcardMatch follows ec2 filter semantics: * matches any sequence, ? any single
+// character and a backslash escapes the next character
+func wildcardMatch(pattern string, s string) bool {
+	p, v := []rune(pattern), []rune(s)
+	// positions to resume from when the last * has to absorb one more character
+	star, resume := -1, 0
+	i, j := 0, 0
+	for j < len(v) {
+		switch {
+		case i < len(p) && p[i] == '*':
+			star, resume = i, j
+			i++
+		case i < len(p) && p[i] == '\\' && i+1 < len(p) && p[i+1] == v[j]:
+			i += 2
+			j++
+		case i < len(p) && p[i] != '\\' && (p[i] == '?' || p[i] == v[j]):
+			i++
+			j++
+		case star >= 0:
+			i = star + 1
+			resume++
+			j = resume
+		default:
+			return false
+		}
+	}
+	for i < len(p) && p[i] == '*' {
+		i++
+	}
+	return i == len(p)
+}
+
+type MatchMode int
+
+const (
+	WildcardMatch MatchMode = iota
+	RegexMatch
+	FuzzyMatch
+)
+
+func ValidateNameTerms(mode MatchMode, terms []string) error {
+	_, err := nameMatcher(mode, terms)
+	return err
+}
+
+func nameMatcher(mode MatchMode, terms []string) (func(name string) bool, error) {
+	switch mode {
+	case RegexMatch:
+		patterns := make([]*regexp.Regexp, len(terms))
+		for i, term := range terms {
+			pattern, err := regexp.Compile("(?i)" + term)
+			if err != nil {
+				return nil, fmt.Errorf("invalid name regular expression %q: %w", term, err)
+			}
+			patterns[i] = pattern
+		}
+		return func(name string) bool {
+			for _, pattern := range patterns {
+				if pattern.MatchString(name) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case FuzzyMatch:
+		return func(name string) bool {
+			return FuzzyScore(terms, name) > 0
+		}, nil
+	}
+	return func(name string) bool {
+		for _, term := range terms {
+			if wildcardMatch(term, name) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// FuzzyScore is the best score of the name against any of the terms, 0 when no term
+// is a case insensitive subsequence of the name
+func FuzzyScore(terms []string, name string) int {
+	best := 0
+	for _, term := range terms {
+		if score := fuzzyScore(term, name); score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// matched characters score a point, with bonuses for runs of consecutive matches,
+// matches at the start of a word and the term appearing as a substring
+func fuzzyScore(term string, name string) int {
+	t, n := []rune(strings.ToLower(term)), []rune(strings.ToLower(name))
+	if len(t) == 0 {
+		return 1
+	}
+	score, ti, run := 0, 0, 0
+	for i := 0; i < len(n) && ti < len(t); i++ {
+		if n[i] != t[ti] {
+			run = 0
+			continue
+		}
+		run++
+		score += 1 + 2*(run-1)
+		if i == 0 || isWordSeparator(n[i-1]) {
+			score += 3
+		}
+		ti++
+	}
+	if ti < len(t) {
+		return 0
+	}
+	if strings.Contains(string(n), string(t)) {
+		score += len(t)
+	}
+	score -= (len(n) - len(t)) / 4
+	if score < 1 {
+		score = 1
+	}
+	return score
+}
+
+func isWordSeparator(r rune) bool {
+	return strings.ContainsRune("-_./: ", r)
+}
@@ -0,0 +1,98 @@
+package ec2
+
+import "testing"
+
+func TestWildcardMatch(t *testing.T) {
+	var data = []struct {
+		pattern  string
+		s        string
+		expected bool
+	}{
+		{"", "", true},
+		{"", "a", false},
+		{"web", "web", true},
+		{"web", "Web", false},
+		{"web", "web-1", false},
+		{"web-*", "web-1", true},
+		{"web-*", "web-", true},
+		{"*-canary*", "web-canary-2", true},
+		{"*", "", true},
+		{"**", "anything", true},
+		{"w?b", "wab", true},
+		{"w?b", "wb", false},
+		{"a*b*c", "aXXbYYc", true},
+		{"a*b*c", "aXXbYY", false},
+		{"*a", "banana", true},
+		{`web\*`, "web*", true},
+		{`web\*`, "web-1", false},
+		{`what\?`, "what?", true},
+		{"名前-*", "名前-1", true},
+	}
+	for _, d := range data {
+		if result := wildcardMatch(d.pattern, d.s); result != d.expected {
+			t.Errorf("wildcardMatch(%q, %q) got %v, want %v", d.pattern, d.s, result, d.expected)
+		}
+	}
+}
+
+func TestNameMatcher(t *testing.T) {
+	var data = []struct {
+		mode     MatchMode
+		terms    []string
+		name     string
+		expected bool
+	}{
+		{WildcardMatch, []string{"web-*"}, "web-1", true},
+		{WildcardMatch, []string{"web-*"}, "WEB-1", false},
+		{RegexMatch, []string{"^web-[0-9]+$"}, "web-12", true},
+		{RegexMatch, []string{"^web-[0-9]+$"}, "WEB-12", true},
+		{RegexMatch, []string{"^web-[0-9]+$"}, "web-canary", false},
+		{RegexMatch, []string{"db", "cache"}, "redis-cache-1", true},
+		{FuzzyMatch, []string{"wbprd"}, "web-prod-1", true},
+		{FuzzyMatch, []string{"WBPRD"}, "web-prod-1", true},
+		{FuzzyMatch, []string{"wbprd"}, "web-stage-1", false},
+	}
+	for _, d := range data {
+		matches, err := nameMatcher(d.mode, d.terms)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result := matches(d.name); result != d.expected {
+			t.Errorf("mode %d %v matching %q got %v, want %v", d.mode, d.terms, d.name, result, d.expected)
+		}
+	}
+}
+
+func TestValidateNameTerms(t *testing.T) {
+	if err := ValidateNameTerms(RegexMatch, []string{"web-("}); err == nil {
+		t.Error("expected error for invalid regular expression")
+	}
+	if err := ValidateNameTerms(WildcardMatch, []string{"web-("}); err != nil {
+		t.Errorf("unexpected error for wildcard term: %v", err)
+	}
+}
+
+func TestFuzzyScoreRanking(t *testing.T) {
+	var data = []struct {
+		term   string
+		better string
+		worse  string
+	}{
+		{"web", "web-1", "my-w-e-b-server"},
+		{"web", "web", "web-server-with-a-long-name"},
+		{"wp", "web-prod", "twisted-pipe"},
+		{"prod", "prod-db", "p-r-o-d"},
+	}
+	for _, d := range data {
+		better, worse := FuzzyScore([]string{d.term}, d.better), FuzzyScore([]string{d.term}, d.worse)
+		if better <= worse {
+			t.Errorf("%q: score for %q (%d) should beat %q (%d)", d.term, d.better, better, d.worse, worse)
+		}
+	}
+	if score := FuzzyScore([]string{"xyz"}, "web-prod"); score != 0 {
+		t.Errorf("non matching score got %d, want 0", score)
+	}
+	if score := FuzzyScore([]string{"xyz", "web"}, "web-prod"); score == 0 {
+		t.Error("expected best score over terms to match")
+	}
+}
@@ -0,0 +1,114 @@
+package ec2
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/jmespath/go-jmespath"
+)
+
+func ValidateQuery(expression string) error {
+	_, err := jmespath.Compile(expression)
+	if err != nil {
+		return fmt.Errorf("invalid query %q: %w", expression, err)
+	}
+	return nil
+}
+
+// Query runs a jmespath expression over the results merged into a single
+// DescribeInstancesOutput. The output is converted to json first so field
+// names and values match what the aws cli queries.
+func Query(results []Result, expression string) (interface{}, error) {
+	merged := ec2.DescribeInstancesOutput{}
+	for _, result := range results {
+		if result.Err == nil && result.Output != nil {
+			merged.Reservations = append(merged.Reservations, result.Output.Reservations...)
+		}
+	}
+	b, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	var data interface{}
+	err = json.Unmarshal(b, &data)
+	if err != nil {
+		return nil, err
+	}
+	return jmespath.Search(expression, data)
+}
+
+// QueryRows lays a query result out as rows. A list of objects gets a header
+// of the object keys, anything else has no header.
+func QueryRows(value interface{}) ([]string, [][]string) {
+	list, ok := value.([]interface{})
+	if !ok {
+		list = []interface{}{value}
+	}
+	header := objectKeys(list)
+	rows := make([][]string, 0, len(list))
+	for _, item := range list {
+		switch v := item.(type) {
+		case []interface{}:
+			row := make([]string, len(v))
+			for i, cell := range v {
+				row[i] = queryCell(cell)
+			}
+			rows = append(rows, row)
+		case map[string]interface{}:
+			if header == nil {
+				rows = append(rows, []string{queryCell(v)})
+				continue
+			}
+			row := make([]string, len(header))
+			for i, key := range header {
+				row[i] = queryCell(v[key])
+			}
+			rows = append(rows, row)
+		default:
+			rows = append(rows, []string{queryCell(v)})
+		}
+	}
+	return header, rows
+}
+
+// the keys when every item is an object, otherwise nil
+func objectKeys(list []interface{}) []string {
+	seen := make(map[string]bool)
+	keys := make([]string, 0)
+	for _, item := range list {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for key := range object {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// null is shown as None, as the aws cli text output does
+func queryCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "None"
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	b, _ := json.Marshal(value)
+	return string(b)
+}
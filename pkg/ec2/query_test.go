@@ -0,0 +1,92 @@
+package ec2
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func createQueryTestResults() []Result {
+	lTime, _ := time.Parse(time.RFC3339, "2021-09-26T19:21:42Z")
+	running := types.InstanceState{Name: types.InstanceStateNameRunning}
+	stopped := types.InstanceState{Name: types.InstanceStateNameStopped}
+	return []Result{
+		{Region: "us-east-1", Output: &ec2.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: []types.Instance{
+			createInstance(mkStrRef("web"), "i-1", mkStrRef("10.0.0.1"), "us-east-1a", running, types.InstanceTypeT3Micro, lTime, "ami-1", []types.Tag{}),
+		}}}}},
+		{Region: "eu-west-1", Err: errors.New("access denied")},
+		{Region: "eu-west-2", Output: &ec2.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: []types.Instance{
+			createInstance(mkStrRef("db"), "i-2", nil, "eu-west-2a", stopped, types.InstanceTypeT3Micro, lTime, "ami-1", []types.Tag{}),
+		}}}}},
+	}
+}
+
+func TestQuery(t *testing.T) {
+	var data = []struct {
+		expression string
+		expected   interface{}
+	}{
+		{"Reservations[].Instances[].[InstanceId,State.Name]",
+			[]interface{}{[]interface{}{"i-1", "running"}, []interface{}{"i-2", "stopped"}}},
+		{"Reservations[].Instances[?State.Name=='running'].InstanceId[]", []interface{}{"i-1"}},
+		{"Reservations[].Instances[].{id: InstanceId, ip: PrivateIpAddress}",
+			[]interface{}{map[string]interface{}{"id": "i-1", "ip": "10.0.0.1"}, map[string]interface{}{"id": "i-2", "ip": nil}}},
+		{"length(Reservations[].Instances[])", float64(2)},
+		{"Reservations[].Instances[].LaunchTime | [0]", "2021-09-26T19:21:42Z"},
+	}
+	for _, d := range data {
+		t.Run(d.expression, func(t *testing.T) {
+			value, err := Query(createQueryTestResults(), d.expression)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(value, d.expected) {
+				t.Errorf("value got %#v, want %#v", value, d.expected)
+			}
+		})
+	}
+}
+
+func TestValidateQuery(t *testing.T) {
+	if err := ValidateQuery("Reservations[].Instances[].InstanceId"); err != nil {
+		t.Errorf("err got %v, want nil", err)
+	}
+	if err := ValidateQuery("Reservations[.Instances"); err == nil {
+		t.Errorf("expected an error, did not get one")
+	}
+}
+
+func TestQueryRows(t *testing.T) {
+	var data = []struct {
+		testName       string
+		value          interface{}
+		expectedHeader []string
+		expectedRows   [][]string
+	}{
+		{"list of lists",
+			[]interface{}{[]interface{}{"i-1", "running"}, []interface{}{"i-2", nil}},
+			nil, [][]string{{"i-1", "running"}, {"i-2", "None"}}},
+		{"list of objects",
+			[]interface{}{map[string]interface{}{"ip": "10.0.0.1", "id": "i-1"}, map[string]interface{}{"id": "i-2", "count": float64(2)}},
+			[]string{"count", "id", "ip"}, [][]string{{"None", "i-1", "10.0.0.1"}, {"2", "i-2", "None"}}},
+		{"list of scalars", []interface{}{"i-1", true}, nil, [][]string{{"i-1"}, {"true"}}},
+		{"scalar", float64(2.5), nil, [][]string{{"2.5"}}},
+		{"nested", []interface{}{[]interface{}{[]interface{}{"a", "b"}}}, nil, [][]string{{`["a","b"]`}}},
+		{"empty", []interface{}{}, nil, [][]string{}},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			header, rows := QueryRows(d.value)
+			if !reflect.DeepEqual(header, d.expectedHeader) {
+				t.Errorf("header got %v, want %v", header, d.expectedHeader)
+			}
+			if !reflect.DeepEqual(rows, d.expectedRows) {
+				t.Errorf("rows got %v, want %v", rows, d.expectedRows)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package ec2
+
+import (
+	"context"
+	"sort"
+	"utils/aws/pkg/account"
+	"utils/aws/pkg/parallel"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+const (
+	regionWorkers   = 8
+	accountWorkers  = 4
+	discoveryRegion = "us-east-1"
+)
+
+type Result struct {
+	Account string
+	Region  string
+	Output  *ec2.DescribeInstancesOutput
+	Err     error
+	// instances in Options.HiddenStates that were left out, counted from one
+	// page of results, HiddenMore is set when there were more pages
+	Hidden     int
+	HiddenMore bool
+}
+
+type regionLister interface {
+	DescribeRegions(ctx context.Context, params *ec2.DescribeRegionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRegionsOutput, error)
+}
+
+func EnabledRegions(ctx context.Context, cfg aws.Config) ([]string, error) {
+	return enabledRegions(ctx, ec2.NewFromConfig(cfg))
+}
+
+// without AllRegions, DescribeRegions only returns regions enabled for the account
+func enabledRegions(ctx context.Context, lister regionLister) ([]string, error) {
+	output, err := lister.DescribeRegions(ctx, &ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, ClassifyError(err)
+	}
+	regions := make([]string, 0, len(output.Regions))
+	for _, region := range output.Regions {
+		regions = append(regions, *region.RegionName)
+	}
+	sort.Strings(regions)
+	return regions, nil
+}
+
+func GetInstancesInRegions(ctx context.Context, cfg aws.Config, regions []string, search []string, opts Options) []Result {
+	return getInstancesInRegions(ctx, func(region string) instanceFinder {
+		return ec2.NewFromConfig(cfg, func(o *ec2.Options) {
+			o.Region = region
+		})
+	}, regions, search, opts)
+}
+
+func getInstancesInRegions(ctx context.Context, finderFor func(region string) instanceFinder, regions []string, search []string, opts Options) []Result {
+	results := make([]Result, len(regions))
+	parallel.ForEach(len(regions), regionWorkers, func(i int) {
+		finder := finderFor(regions[i])
+		output, err := getInstances(ctx, finder, search, opts)
+		results[i] = Result{Region: regions[i], Output: output, Err: err}
+		if err == nil && len(opts.HiddenStates) > 0 {
+			// the count is informational, failing to get it should not fail the search
+			results[i].Hidden, results[i].HiddenMore, _ = countInstancesInStates(ctx, finder, search, opts, opts.HiddenStates)
+		}
+	})
+	return results
+}
+
+func GetInstancesInAccounts(ctx context.Context, accounts []account.Account, regions []string, allRegions bool, search []string, opts Options) []Result {
+	return getInstancesInAccounts(accounts, func(acct account.Account) []Result {
+		accountRegions, err := resolveRegions(ctx, acct.Config, regions, allRegions)
+		if err != nil {
+			return []Result{{Region: acct.Config.Region, Err: err}}
+		}
+		return GetInstancesInRegions(ctx, acct.Config, accountRegions, search, opts)
+	})
+}
+
+func getInstancesInAccounts(accounts []account.Account, search func(acct account.Account) []Result) []Result {
+	perAccount := make([][]Result, len(accounts))
+	parallel.ForEach(len(accounts), accountWorkers, func(i int) {
+		results := search(accounts[i])
+		for j := range results {
+			results[j].Account = accounts[i].Label()
+		}
+		perAccount[i] = results
+	})
+	var results = make([]Result, 0, len(accounts))
+	for _, accountResults := range perAccount {
+		results = append(results, accountResults...)
+	}
+	return results
+}
+
+// regions enabled can differ between accounts, so discovery is done per account
+func resolveRegions(ctx context.Context, cfg aws.Config, regions []string, allRegions bool) ([]string, error) {
+	if allRegions {
+		if cfg.Region == "" {
+			cfg.Region = discoveryRegion
+		}
+		return EnabledRegions(ctx, cfg)
+	}
+	if len(regions) > 0 {
+		return regions, nil
+	}
+	return []string{cfg.Region}, nil
+}
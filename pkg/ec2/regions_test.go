@@ -0,0 +1,190 @@
+package ec2
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"utils/aws/pkg/account"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+type regionListerMock struct {
+	output *ec2.DescribeRegionsOutput
+	err    error
+}
+
+func (rlm regionListerMock) DescribeRegions(ctx context.Context, params *ec2.DescribeRegionsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeRegionsOutput, error) {
+	return rlm.output, rlm.err
+}
+
+func TestEnabledRegions(t *testing.T) {
+	mock := regionListerMock{output: &ec2.DescribeRegionsOutput{Regions: []types.Region{
+		{RegionName: mkStrRef("us-east-1")},
+		{RegionName: mkStrRef("eu-west-1")},
+		{RegionName: mkStrRef("ap-southeast-2")},
+	}}}
+	regions, err := enabledRegions(nil, mock)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"ap-southeast-2", "eu-west-1", "us-east-1"}
+	if !reflect.DeepEqual(regions, expected) {
+		t.Errorf("regions got %v, want %v", regions, expected)
+	}
+}
+
+func TestEnabledRegionsError(t *testing.T) {
+	apiErr := errors.New("boom")
+	_, err := enabledRegions(nil, regionListerMock{err: apiErr})
+	if !errors.Is(err, apiErr) {
+		t.Errorf("err got %v, want %v", err, apiErr)
+	}
+}
+
+func TestGetInstancesInRegions(t *testing.T) {
+	regionErr := errors.New("region unavailable")
+	finders := map[string]*instanceFinderMock{
+		"us-east-1": {pages: createPages(2)},
+		"eu-west-1": {err: regionErr},
+		"us-west-2": {pages: createPages(1, 1)},
+	}
+	regions := []string{"us-east-1", "eu-west-1", "us-west-2"}
+
+	results := getInstancesInRegions(nil, func(region string) instanceFinder {
+		return finders[region]
+	}, regions, []string{"web-*"}, Options{})
+
+	if len(results) != len(regions) {
+		t.Fatalf("number of results got %d, want %d", len(results), len(regions))
+	}
+	var data = []struct {
+		expectedInstances int
+		expectedErr       error
+	}{
+		{2, nil},
+		{0, regionErr},
+		{2, nil},
+	}
+	for i, d := range data {
+		result := results[i]
+		if result.Region != regions[i] {
+			t.Errorf("result %d region got %s, want %s", i, result.Region, regions[i])
+		}
+		if !errors.Is(result.Err, d.expectedErr) {
+			t.Errorf("result %d err got %v, want %v", i, result.Err, d.expectedErr)
+		}
+		if d.expectedErr == nil && countInstances(result.Output) != d.expectedInstances {
+			t.Errorf("result %d instances got %d, want %d", i, countInstances(result.Output), d.expectedInstances)
+		}
+	}
+}
+
+func TestGetInstancesInAccounts(t *testing.T) {
+	accounts := []account.Account{
+		{ID: "111111111111", Alias: "prod"},
+		{ID: "222222222222"},
+	}
+	results := getInstancesInAccounts(accounts, func(acct account.Account) []Result {
+		if acct.ID == "222222222222" {
+			return []Result{{Region: "us-east-1", Err: errors.New("assume role failed")}}
+		}
+		return []Result{{Region: "us-east-1"}, {Region: "eu-west-1"}}
+	})
+	var expected = []struct {
+		account string
+		region  string
+		failed  bool
+	}{
+		{"111111111111 (prod)", "us-east-1", false},
+		{"111111111111 (prod)", "eu-west-1", false},
+		{"222222222222", "us-east-1", true},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("number of results got %d, want %d", len(results), len(expected))
+	}
+	for i, e := range expected {
+		if results[i].Account != e.account || results[i].Region != e.region || (results[i].Err != nil) != e.failed {
+			t.Errorf("result %d got %+v, want %+v", i, results[i], e)
+		}
+	}
+}
+
+func TestResolveRegions(t *testing.T) {
+	var data = []struct {
+		cfgRegion string
+		regions   []string
+		expected  []string
+	}{
+		{"eu-west-2", []string{}, []string{"eu-west-2"}},
+		{"eu-west-2", []string{"us-east-1", "ap-south-1"}, []string{"us-east-1", "ap-south-1"}},
+	}
+	for _, d := range data {
+		regions, err := resolveRegions(nil, aws.Config{Region: d.cfgRegion}, d.regions, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(regions, d.expected) {
+			t.Errorf("regions got %v, want %v", regions, d.expected)
+		}
+	}
+}
+
+type finderFunc func(params *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error)
+
+func (f finderFunc) DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	return f(params)
+}
+
+func TestGetInstancesInRegionsHidden(t *testing.T) {
+	var data = []struct {
+		testName            string
+		opts                Options
+		hiddenPages         []int
+		expectedVisible     int
+		expectedHidden      int
+		expectedHiddenMore  bool
+		expectedHiddenCalls int
+	}{
+		{"hidden states counted", Options{States: DefaultStates, HiddenStates: HiddenStates}, []int{3}, 1, 3, false, 1},
+		{"not counted without hidden states", Options{States: DefaultStates}, []int{3}, 1, 0, false, 0},
+		{"limit does not apply to the count", Options{Limit: 1, States: DefaultStates, HiddenStates: HiddenStates}, []int{3}, 1, 3, false, 1},
+		{"only the first page is counted", Options{States: DefaultStates, HiddenStates: HiddenStates}, []int{3, 2}, 1, 3, true, 1},
+		{"exclusions apply to the count", Options{States: DefaultStates, HiddenStates: HiddenStates, Exclude: []string{"i-2"}}, []int{3}, 1, 2, false, 1},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			hiddenCalls := 0
+			// one instance in any default state, the rest shutting-down or terminated
+			finder := finderFunc(func(params *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+				states := params.Filters[len(params.Filters)-1].Values
+				if reflect.DeepEqual(states, HiddenStates) {
+					hiddenCalls++
+					if params.MaxResults == nil || *params.MaxResults != maxPageSize {
+						t.Errorf("MaxResults got %v, want %d", params.MaxResults, maxPageSize)
+					}
+					return createPages(d.hiddenPages...)[0], nil
+				}
+				if !reflect.DeepEqual(states, DefaultStates) {
+					t.Errorf("states got %v, want %v", states, DefaultStates)
+				}
+				return createPages(1)[0], nil
+			})
+			results := getInstancesInRegions(nil, func(region string) instanceFinder {
+				return finder
+			}, []string{"us-east-1"}, []string{"web-*"}, d.opts)
+			if countInstances(results[0].Output) != d.expectedVisible {
+				t.Errorf("instances got %d, want %d", countInstances(results[0].Output), d.expectedVisible)
+			}
+			if results[0].Hidden != d.expectedHidden || results[0].HiddenMore != d.expectedHiddenMore {
+				t.Errorf("hidden got %d more %v, want %d more %v", results[0].Hidden, results[0].HiddenMore, d.expectedHidden, d.expectedHiddenMore)
+			}
+			if hiddenCalls != d.expectedHiddenCalls {
+				t.Errorf("hidden calls got %d, want %d", hiddenCalls, d.expectedHiddenCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package ec2
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"utils/aws/pkg/table"
+)
+
+var stateColors = map[string]string{
+	"running":       table.Green,
+	"stopped":       table.Yellow,
+	"shutting-down": table.Grey,
+	"terminated":    table.Grey,
+}
+
+// Styler colours instance states and highlights where name terms matched the name
+type Styler struct {
+	mode  MatchMode
+	terms []string
+}
+
+func NewStyler(mode MatchMode, terms []string) Styler {
+	return Styler{mode: mode, terms: terms}
+}
+
+func (s Styler) Header(heading string) string {
+	return table.Colorize(table.Bold, heading)
+}
+
+func (s Styler) Cell(column string, value string) string {
+	switch column {
+	case "state":
+		if color, ok := stateColors[value]; ok {
+			return table.Colorize(color, value)
+		}
+	case "name":
+		return highlight(value, s.matchedRunes(value))
+	}
+	return value
+}
+
+func (s Styler) matchedRunes(name string) []bool {
+	marks := make([]bool, len([]rune(name)))
+	for _, term := range s.terms {
+		switch s.mode {
+		case RegexMatch:
+			markRegex(marks, name, term)
+		case FuzzyMatch:
+			markFuzzy(marks, name, term)
+		default:
+			markWildcard(marks, name, term)
+		}
+	}
+	return marks
+}
+
+// the literal parts of the pattern, found left to right
+func markWildcard(marks []bool, name string, pattern string) {
+	if !wildcardMatch(pattern, name) {
+		return
+	}
+	n := []rune(name)
+	pos := 0
+	for _, literal := range wildcardLiterals(pattern) {
+		i := indexRunes(n[pos:], literal)
+		if i < 0 {
+			return
+		}
+		for j := range literal {
+			marks[pos+i+j] = true
+		}
+		pos += i + len(literal)
+	}
+}
+
+func wildcardLiterals(pattern string) [][]rune {
+	var literals [][]rune
+	var current []rune
+	p := []rune(pattern)
+	for i := 0; i < len(p); i++ {
+		switch {
+		case p[i] == '\\' && i+1 < len(p):
+			i++
+			current = append(current, p[i])
+		case p[i] == '*' || p[i] == '?':
+			if len(current) > 0 {
+				literals = append(literals, current)
+				current = nil
+			}
+		default:
+			current = append(current, p[i])
+		}
+	}
+	if len(current) > 0 {
+		literals = append(literals, current)
+	}
+	return literals
+}
+
+func indexRunes(s []rune, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if string(s[i:i+len(sub)]) == string(sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+func markRegex(marks []bool, name string, term string) {
+	pattern, err := regexp.Compile("(?i)" + term)
+	if err != nil {
+		return
+	}
+	for _, match := range pattern.FindAllStringIndex(name, -1) {
+		runeIndex := 0
+		for byteIndex := range name {
+			if byteIndex >= match[0] && byteIndex < match[1] {
+				marks[runeIndex] = true
+			}
+			runeIndex++
+		}
+	}
+}
+
+// the first occurrence of each term character in turn, as fuzzyScore matches
+func markFuzzy(marks []bool, name string, term string) {
+	if fuzzyScore(term, name) == 0 {
+		return
+	}
+	t := []rune(strings.ToLower(term))
+	ti := 0
+	for i, r := range []rune(name) {
+		if ti < len(t) && unicode.ToLower(r) == t[ti] {
+			marks[i] = true
+			ti++
+		}
+	}
+}
+
+func highlight(s string, marks []bool) string {
+	var b strings.Builder
+	var run []rune
+	flush := func(marked bool) {
+		if marked {
+			b.WriteString(table.Colorize(table.Highlight, string(run)))
+		} else {
+			b.WriteString(string(run))
+		}
+		run = run[:0]
+	}
+	for i, r := range []rune(s) {
+		if i > 0 && marks[i] != marks[i-1] {
+			flush(marks[i-1])
+		}
+		run = append(run, r)
+	}
+	if len(run) > 0 {
+		flush(marks[len(marks)-1])
+	}
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package ec2
+
+import (
+	"strings"
+	"testing"
+	"utils/aws/pkg/table"
+)
+
+func TestStylerCell(t *testing.T) {
+	h := func(s string) string { return table.Colorize(table.Highlight, s) }
+	var data = []struct {
+		testName string
+		mode     MatchMode
+		terms    []string
+		column   string
+		value    string
+		expected string
+	}{
+		{"running", WildcardMatch, nil, "state", "running", table.Colorize(table.Green, "running")},
+		{"stopped", WildcardMatch, nil, "state", "stopped", table.Colorize(table.Yellow, "stopped")},
+		{"terminated", WildcardMatch, nil, "state", "terminated", table.Colorize(table.Grey, "terminated")},
+		{"pending is plain", WildcardMatch, nil, "state", "pending", "pending"},
+		{"other columns are plain", WildcardMatch, []string{"web"}, "id", "web", "web"},
+		{"exact name", WildcardMatch, []string{"web"}, "name", "web", h("web")},
+		{"wildcard", WildcardMatch, []string{"web-*-1"}, "name", "web-prod-1", h("web-") + "prod" + h("-1")},
+		{"escaped wildcard", WildcardMatch, []string{`a\*?`}, "name", "a*b", h("a*") + "b"},
+		{"no match", WildcardMatch, []string{"db*"}, "name", "web", "web"},
+		{"regex", RegexMatch, []string{"PROD"}, "name", "web-prod-1", "web-" + h("prod") + "-1"},
+		{"fuzzy", FuzzyMatch, []string{"wp1"}, "name", "web-prod-1", h("w") + "eb-" + h("p") + "rod-" + h("1")},
+		{"several terms", WildcardMatch, []string{"db*", "*prod*"}, "name", "web-prod", "web-" + h("prod")},
+		{"unicode", RegexMatch, []string{"é"}, "name", "café-1", "caf" + h("é") + "-1"},
+		{"empty name", WildcardMatch, []string{"*"}, "name", "", ""},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			styled := NewStyler(d.mode, d.terms).Cell(d.column, d.value)
+			if styled != d.expected {
+				t.Errorf("cell got %q, want %q", styled, d.expected)
+			}
+			if table.VisibleWidth(styled) != len([]rune(d.value)) {
+				t.Errorf("visible width got %d, want %d", table.VisibleWidth(styled), len([]rune(d.value)))
+			}
+		})
+	}
+}
+
+func TestStylerHeader(t *testing.T) {
+	header := NewStyler(WildcardMatch, nil).Header("name")
+	if !strings.HasPrefix(header, table.Bold) || table.VisibleWidth(header) != 4 {
+		t.Errorf("header got %q, want bold name", header)
+	}
+}
@@ -0,0 +1,30 @@
+package ec2
+
+import "strings"
+
+// TagKeyMatcher keeps tag keys matching any of the wildcard patterns, patterns
+// prefixed with ! remove keys instead. With only ! patterns every other key is kept.
+func TagKeyMatcher(patterns []string) func(key string) bool {
+	var include, exclude []string
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			exclude = append(exclude, strings.TrimPrefix(pattern, "!"))
+		} else {
+			include = append(include, pattern)
+		}
+	}
+	matchesAny := func(patterns []string, key string) bool {
+		for _, pattern := range patterns {
+			if wildcardMatch(pattern, key) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(key string) bool {
+		if len(include) > 0 && !matchesAny(include, key) {
+			return false
+		}
+		return !matchesAny(exclude, key)
+	}
+}
@@ -0,0 +1,35 @@
+package ec2
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTagKeyMatcher(t *testing.T) {
+	keys := []string{"Name", "team", "env", "aws:cloudformation:stack-name", "aws:autoscaling:groupName"}
+	var data = []struct {
+		patterns []string
+		expected []string
+	}{
+		{[]string{"team", "env"}, []string{"team", "env"}},
+		{[]string{"aws:*"}, []string{"aws:cloudformation:stack-name", "aws:autoscaling:groupName"}},
+		{[]string{"!aws:*"}, []string{"Name", "team", "env"}},
+		{[]string{"aws:*", "!aws:autoscaling:*"}, []string{"aws:cloudformation:stack-name"}},
+		{[]string{"Team"}, []string{}},
+	}
+	for _, d := range data {
+		t.Run(strings.Join(d.patterns, ","), func(t *testing.T) {
+			keep := TagKeyMatcher(d.patterns)
+			kept := make([]string, 0)
+			for _, key := range keys {
+				if keep(key) {
+					kept = append(kept, key)
+				}
+			}
+			if !reflect.DeepEqual(kept, d.expected) {
+				t.Errorf("kept got %v, want %v", kept, d.expected)
+			}
+		})
+	}
+}
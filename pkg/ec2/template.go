@@ -0,0 +1,156 @@
+package ec2
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// View is what a template is executed against, once per instance. Fields that
+// are not set on the instance are empty strings rather than "-".
+type View struct {
+	Account        string
+	Region         string
+	Name           string
+	InstanceID     string
+	PrivateIP      string
+	PublicIP       string
+	PrivateDNS     string
+	PublicDNS      string
+	AZ             string
+	State          string
+	Type           string
+	ImageID        string
+	VpcID          string
+	SubnetID       string
+	KeyName        string
+	Platform       string
+	Arch           string
+	LaunchTime     time.Time
+	SecurityGroups []string
+	Tags           map[string]string
+}
+
+func (v View) Tag(key string) string {
+	return v.Tags[key]
+}
+
+func NewView(result Result, instance types.Instance) View {
+	view := View{
+		Account:        result.Account,
+		Region:         result.Region,
+		InstanceID:     stringValue(instance.InstanceId),
+		PrivateIP:      stringValue(instance.PrivateIpAddress),
+		PublicIP:       stringValue(instance.PublicIpAddress),
+		PrivateDNS:     stringValue(instance.PrivateDnsName),
+		PublicDNS:      stringValue(instance.PublicDnsName),
+		Type:           string(instance.InstanceType),
+		ImageID:        stringValue(instance.ImageId),
+		VpcID:          stringValue(instance.VpcId),
+		SubnetID:       stringValue(instance.SubnetId),
+		KeyName:        stringValue(instance.KeyName),
+		Platform:       stringValue(instance.PlatformDetails),
+		Arch:           string(instance.Architecture),
+		SecurityGroups: make([]string, 0, len(instance.SecurityGroups)),
+		Tags:           make(map[string]string, len(instance.Tags)),
+	}
+	if instance.Placement != nil {
+		view.AZ = stringValue(instance.Placement.AvailabilityZone)
+	}
+	if instance.State != nil {
+		view.State = string(instance.State.Name)
+	}
+	if instance.LaunchTime != nil {
+		view.LaunchTime = *instance.LaunchTime
+	}
+	for _, group := range instance.SecurityGroups {
+		view.SecurityGroups = append(view.SecurityGroups, stringValue(group.GroupName))
+	}
+	for _, tag := range instance.Tags {
+		view.Tags[stringValue(tag.Key)] = stringValue(tag.Value)
+	}
+	view.Name = view.Tags["Name"]
+	return view
+}
+
+var now = time.Now
+
+// ParseTemplate parses text with the template functions available:
+//
+//	tag "Owner"          value of a tag on the current instance
+//	join ", " .List      joins a list of strings
+//	default "x" .Field   the field, or x when it is empty
+//	age .LaunchTime      time since, as e.g. 3d4h
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("instance").Funcs(template.FuncMap{
+		"tag":     func(key string) string { return "" },
+		"join":    func(sep string, list []string) string { return strings.Join(list, sep) },
+		"default": defaultValue,
+		"age":     func(t time.Time) string { return FormatAge(now().Sub(t)) },
+	}).Parse(text)
+}
+
+func defaultValue(fallback string, value string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// Views lists every instance in the results, skipping failed results
+func Views(results []Result) []View {
+	var views = make([]View, 0)
+	for _, result := range results {
+		if result.Err != nil || result.Output == nil {
+			continue
+		}
+		for _, reservation := range result.Output.Reservations {
+			for _, instance := range reservation.Instances {
+				views = append(views, NewView(result, instance))
+			}
+		}
+	}
+	return views
+}
+
+// ExecuteTemplate writes the template output for each instance followed by a newline
+func ExecuteTemplate(w io.Writer, tmpl *template.Template, results []Result) error {
+	for _, view := range Views(results) {
+		// tag needs the current instance so is rebound per execution
+		instanceTmpl, err := tmpl.Clone()
+		if err != nil {
+			return err
+		}
+		instanceTmpl.Funcs(template.FuncMap{"tag": view.Tag})
+		err = instanceTmpl.Execute(w, view)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatAge rounds down to the two largest units, e.g. 3d4h, 5h12m or 7m
+func FormatAge(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
@@ -0,0 +1,83 @@
+package ec2
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+func TestExecuteTemplate(t *testing.T) {
+	lTime, _ := time.Parse(time.RFC3339, "2021-09-26T19:21:42Z")
+	now = func() time.Time { return lTime.Add(50 * time.Hour) }
+	defer func() { now = time.Now }()
+	running := types.InstanceState{Name: types.InstanceStateNameRunning}
+	web := createInstance(mkStrRef("web"), "i-1", mkStrRef("10.0.0.1"), "us-east-1a", running, types.InstanceTypeT3Micro, lTime, "ami-1",
+		[]types.Tag{{Key: mkStrRef("Owner"), Value: mkStrRef("alice")}})
+	web.SecurityGroups = []types.GroupIdentifier{{GroupName: mkStrRef("web")}, {GroupName: mkStrRef("ssh")}}
+	db := createInstance(nil, "i-2", nil, "us-east-1b", running, types.InstanceTypeT3Micro, lTime, "ami-1", []types.Tag{})
+	results := []Result{
+		{Region: "us-east-1", Output: &ec2.DescribeInstancesOutput{Reservations: []types.Reservation{{Instances: []types.Instance{web, db}}}}},
+		{Region: "eu-west-1", Err: errors.New("access denied")},
+	}
+	var data = []struct {
+		text     string
+		expected string
+	}{
+		{"{{.Name}} {{.PrivateIP}}", "web 10.0.0.1\n \n"},
+		{"{{.InstanceID}} {{tag \"Owner\"}}", "i-1 alice\ni-2 \n"},
+		{"{{.Tag \"Owner\" | default \"nobody\"}}", "alice\nnobody\n"},
+		{"{{join \",\" .SecurityGroups}}", "web,ssh\n\n"},
+		{"{{.Region}} {{age .LaunchTime}}", "us-east-1 2d2h\nus-east-1 2d2h\n"},
+		{"ssh {{.PrivateIP | default .InstanceID}}", "ssh 10.0.0.1\nssh i-2\n"},
+	}
+	for _, d := range data {
+		t.Run(d.text, func(t *testing.T) {
+			tmpl, err := ParseTemplate(d.text)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var buf bytes.Buffer
+			err = ExecuteTemplate(&buf, tmpl, results)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != d.expected {
+				t.Errorf("output got %q, want %q", buf.String(), d.expected)
+			}
+		})
+	}
+}
+
+func TestParseTemplateErrors(t *testing.T) {
+	for _, text := range []string{"{{.Name", "{{unknown .Name}}"} {
+		t.Run(text, func(t *testing.T) {
+			_, err := ParseTemplate(text)
+			if err == nil {
+				t.Errorf("expected an error, did not get one")
+			}
+		})
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	var data = []struct {
+		age      time.Duration
+		expected string
+	}{
+		{-time.Minute, "0m"},
+		{7*time.Minute + 30*time.Second, "7m"},
+		{5*time.Hour + 12*time.Minute, "5h12m"},
+		{76 * time.Hour, "3d4h"},
+	}
+	for _, d := range data {
+		t.Run(d.age.String(), func(t *testing.T) {
+			if age := FormatAge(d.age); age != d.expected {
+				t.Errorf("age got %q, want %q", age, d.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package pager
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const defaultCommand = "less"
+
+// ErrClosed is returned by Write once the pager has stopped reading, usually
+// because the user quit before reaching the end of the output
+var ErrClosed = errors.New("pager closed")
+
+type Pager struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	closed bool
+}
+
+// Command is $AWSI_PAGER, then $PAGER, then less
+func Command() string {
+	for _, name := range []string{"AWSI_PAGER", "PAGER"} {
+		if command := strings.TrimSpace(os.Getenv(name)); command != "" {
+			return command
+		}
+	}
+	return defaultCommand
+}
+
+// Start runs command with its output going to stdout and stderr. The command
+// is split on spaces, it is not run by a shell.
+func Start(command string, stdout io.Writer, stderr io.Writer) (*Pager, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("empty pager command")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// as git does, quit if one screen is enough, keep colours and leave the output on screen
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+	return &Pager{cmd: cmd, stdin: stdin}, nil
+}
+
+func (p *Pager) Write(b []byte) (int, error) {
+	if p.closed {
+		return 0, ErrClosed
+	}
+	n, err := p.stdin.Write(b)
+	if err != nil {
+		p.closed = true
+		return n, ErrClosed
+	}
+	return n, nil
+}
+
+// Close waits for the user to quit the pager
+func (p *Pager) Close() error {
+	p.stdin.Close()
+	return p.cmd.Wait()
+}
@@ -0,0 +1,93 @@
+package pager
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	var data = []struct {
+		awsiPager string
+		pager     string
+		expected  string
+	}{
+		{"", "", "less"},
+		{"", "more", "more"},
+		{"less -S", "more", "less -S"},
+		{" ", "more", "more"},
+	}
+	for _, d := range data {
+		t.Run(d.expected, func(t *testing.T) {
+			defer restoreEnv("AWSI_PAGER")()
+			defer restoreEnv("PAGER")()
+			os.Setenv("AWSI_PAGER", d.awsiPager)
+			os.Setenv("PAGER", d.pager)
+			if command := Command(); command != d.expected {
+				t.Errorf("command got %q, want %q", command, d.expected)
+			}
+		})
+	}
+}
+
+func restoreEnv(name string) func() {
+	value, ok := os.LookupEnv(name)
+	return func() {
+		if ok {
+			os.Setenv(name, value)
+		} else {
+			os.Unsetenv(name)
+		}
+	}
+}
+
+func TestPager(t *testing.T) {
+	var stdout bytes.Buffer
+	p, err := Start("cat", &stdout, os.Stderr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = p.Write([]byte("line 1\nline 2\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = p.Close()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stdout.String() != "line 1\nline 2\n" {
+		t.Errorf("output got %q, want %q", stdout.String(), "line 1\nline 2\n")
+	}
+}
+
+func TestPagerExitsEarly(t *testing.T) {
+	var stdout bytes.Buffer
+	p, err := Start("head -n 1", &stdout, os.Stderr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	line := []byte(strings.Repeat("x", 1024) + "\n")
+	for i := 0; i < 1024 && err == nil; i++ {
+		_, err = p.Write(line)
+	}
+	if !errors.Is(err, ErrClosed) {
+		t.Errorf("err got %v, want %v", err, ErrClosed)
+	}
+	_ = p.Close()
+	if stdout.String() != string(line) {
+		t.Errorf("output got %d bytes, want %d", stdout.Len(), len(line))
+	}
+}
+
+func TestStartErrors(t *testing.T) {
+	for _, command := range []string{"", "awsi-no-such-pager"} {
+		t.Run(command, func(t *testing.T) {
+			_, err := Start(command, os.Stdout, os.Stderr)
+			if err == nil {
+				t.Errorf("expected an error, did not get one")
+			}
+		})
+	}
+}
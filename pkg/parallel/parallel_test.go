@@ -0,0 +1,48 @@
+package parallel
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestForEach(t *testing.T) {
+	var data = []struct {
+		n       int
+		workers int
+	}{
+		{0, 4},
+		{3, 8},
+		{20, 4},
+		{5, 1},
+	}
+	for _, d := range data {
+		t.Run(fmt.Sprintf("n=%d workers=%d", d.n, d.workers), func(t *testing.T) {
+			var mu sync.Mutex
+			running, maxRunning := 0, 0
+			seen := make([]int, d.n)
+			ForEach(d.n, d.workers, func(i int) {
+				mu.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				seen[i]++
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+			})
+			if maxRunning > d.workers {
+				t.Errorf("max concurrent got %d, want at most %d", maxRunning, d.workers)
+			}
+			for i, count := range seen {
+				if count != 1 {
+					t.Errorf("index %d visited %d times, want 1", i, count)
+				}
+			}
+		})
+	}
+}
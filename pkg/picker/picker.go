@@ -0,0 +1,332 @@
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"unicode/utf8"
+	"utils/aws/pkg/table"
+
+	"golang.org/x/term"
+)
+
+// ErrCancelled is returned by Run when the user leaves without choosing
+var ErrCancelled = errors.New("cancelled")
+
+// Item is a row of the picker, Line is shown and filtered on and Detail is
+// shown in the preview pane while the row is under the cursor
+type Item struct {
+	Line   string
+	Detail string
+}
+
+type Options struct {
+	Header string
+	// Score ranks a line against the query, 0 hides it
+	Score func(query string, line string) int
+}
+
+type key int
+
+const (
+	keyRune key = iota
+	keyBackspace
+	keyClear
+	keyUp
+	keyDown
+	keyToggle
+	keyEnter
+	keyCancel
+)
+
+type event struct {
+	key  key
+	char rune
+}
+
+// parseEvents decodes a read from a raw terminal, a lone escape cancels and
+// escape sequences other than the up and down arrows are ignored
+func parseEvents(b []byte) []event {
+	var events []event
+	for len(b) > 0 {
+		switch {
+		case b[0] == 0x1b && len(b) >= 3 && (b[1] == '[' || b[1] == 'O'):
+			// skip parameters up to the final byte of the sequence
+			end := 2
+			for end < len(b)-1 && b[end] >= 0x30 && b[end] <= 0x3f {
+				end++
+			}
+			switch b[end] {
+			case 'A':
+				events = append(events, event{key: keyUp})
+			case 'B':
+				events = append(events, event{key: keyDown})
+			}
+			b = b[end+1:]
+			continue
+		case b[0] == 0x1b && len(b) == 1:
+			events = append(events, event{key: keyCancel})
+		case b[0] == 0x1b:
+			// alt or an unknown sequence, skip what is left of the read
+			return events
+		case b[0] == 3 || b[0] == 7:
+			events = append(events, event{key: keyCancel})
+		case b[0] == '\r' || b[0] == '\n':
+			events = append(events, event{key: keyEnter})
+		case b[0] == '\t':
+			events = append(events, event{key: keyToggle})
+		case b[0] == 127 || b[0] == 8:
+			events = append(events, event{key: keyBackspace})
+		case b[0] == 21:
+			events = append(events, event{key: keyClear})
+		case b[0] == 16:
+			events = append(events, event{key: keyUp})
+		case b[0] == 14:
+			events = append(events, event{key: keyDown})
+		case b[0] >= ' ':
+			r, size := utf8.DecodeRune(b)
+			events = append(events, event{key: keyRune, char: r})
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return events
+}
+
+type state struct {
+	items    []Item
+	score    func(query string, line string) int
+	query    []rune
+	matches  []int
+	cursor   int
+	offset   int
+	selected map[int]bool
+}
+
+func newState(items []Item, score func(query string, line string) int) *state {
+	s := &state{items: items, score: score, selected: map[int]bool{}}
+	s.update()
+	return s
+}
+
+// update filters the items on the query, best matches first
+func (s *state) update() {
+	query := strings.TrimSpace(string(s.query))
+	s.matches = s.matches[:0]
+	scores := make(map[int]int)
+	for i, item := range s.items {
+		if query == "" {
+			s.matches = append(s.matches, i)
+			continue
+		}
+		if score := s.score(query, item.Line); score > 0 {
+			s.matches = append(s.matches, i)
+			scores[i] = score
+		}
+	}
+	if query != "" {
+		sort.SliceStable(s.matches, func(a, b int) bool {
+			return scores[s.matches[a]] > scores[s.matches[b]]
+		})
+	}
+	s.cursor, s.offset = 0, 0
+}
+
+func (s *state) move(by int) {
+	s.cursor += by
+	if s.cursor < 0 {
+		s.cursor = 0
+	}
+	if s.cursor > len(s.matches)-1 {
+		s.cursor = len(s.matches) - 1
+	}
+	if s.cursor < 0 {
+		s.cursor = 0
+	}
+}
+
+// handle applies an event, done is true once the user has chosen or cancelled
+func (s *state) handle(e event) (done bool, err error) {
+	switch e.key {
+	case keyRune:
+		s.query = append(s.query, e.char)
+		s.update()
+	case keyBackspace:
+		if len(s.query) > 0 {
+			s.query = s.query[:len(s.query)-1]
+			s.update()
+		}
+	case keyClear:
+		s.query = s.query[:0]
+		s.update()
+	case keyUp:
+		s.move(-1)
+	case keyDown:
+		s.move(1)
+	case keyToggle:
+		if len(s.matches) > 0 {
+			i := s.matches[s.cursor]
+			if s.selected[i] {
+				delete(s.selected, i)
+			} else {
+				s.selected[i] = true
+			}
+			s.move(1)
+		}
+	case keyEnter:
+		return len(s.chosen()) > 0, nil
+	case keyCancel:
+		return true, ErrCancelled
+	}
+	return false, nil
+}
+
+// chosen is the selected items in their original order, or the item under
+// the cursor when nothing is selected
+func (s *state) chosen() []int {
+	var chosen []int
+	for i := range s.items {
+		if s.selected[i] {
+			chosen = append(chosen, i)
+		}
+	}
+	if len(chosen) == 0 && len(s.matches) > 0 {
+		chosen = append(chosen, s.matches[s.cursor])
+	}
+	return chosen
+}
+
+// draw writes the whole screen: the query, a count, the header, the rows that
+// fit and a preview of the row under the cursor
+func (s *state) draw(w io.Writer, header string, width int, height int) error {
+	var preview []string
+	if len(s.matches) > 0 {
+		preview = strings.Split(strings.TrimRight(s.items[s.matches[s.cursor]].Detail, "\n"), "\n")
+	}
+	if len(preview) > height/3 {
+		preview = preview[:height/3]
+	}
+	// query, count and header lines, a separator above the preview
+	rows := height - 3 - len(preview) - 1
+	if rows < 1 {
+		rows = 1
+	}
+	if s.cursor < s.offset {
+		s.offset = s.cursor
+	}
+	if s.cursor >= s.offset+rows {
+		s.offset = s.cursor - rows + 1
+	}
+	lines := []string{
+		"> " + string(s.query),
+		fmt.Sprintf("  %d/%d (%d selected) tab to select, enter to choose, esc to cancel", len(s.matches), len(s.items), len(s.selected)),
+		table.Bold + "  " + header + table.Reset,
+	}
+	for n := s.offset; n < len(s.matches) && n < s.offset+rows; n++ {
+		i := s.matches[n]
+		prefix := []byte("  ")
+		if n == s.cursor {
+			prefix[0] = '>'
+		}
+		if s.selected[i] {
+			prefix[1] = '*'
+		}
+		lines = append(lines, string(prefix)+s.items[i].Line)
+	}
+	for len(lines) < 3+rows {
+		lines = append(lines, "")
+	}
+	lines = append(lines, strings.Repeat("-", width))
+	lines = append(lines, preview...)
+	for i, line := range lines {
+		lines[i] = table.Truncate(line, width)
+	}
+	// cursor back to the end of the query line
+	_, err := fmt.Fprintf(w, "\x1b[H\x1b[2J%s\x1b[1;%dH", strings.Join(lines, "\r\n"), 3+table.VisibleWidth(string(s.query)))
+	return err
+}
+
+// Run shows the picker on the terminal in and out until the user chooses, and
+// returns the indexes of the chosen items
+func Run(in *os.File, out *os.File, items []Item, opts Options) ([]int, error) {
+	inFd, outFd := int(in.Fd()), int(out.Fd())
+	if !term.IsTerminal(inFd) || !term.IsTerminal(outFd) {
+		return nil, errors.New("the picker needs a terminal")
+	}
+	oldState, err := term.MakeRaw(inFd)
+	if err != nil {
+		return nil, err
+	}
+	defer term.Restore(inFd, oldState)
+	// alternate screen, restored on the way out
+	fmt.Fprint(out, "\x1b[?1049h")
+	defer fmt.Fprint(out, "\x1b[?1049l")
+	s := newState(items, opts.Score)
+	// resizes only redraw, input is read on its own goroutine so a resize can
+	// interrupt the wait. The reader may be left blocked in Read after Run
+	// returns, its next read is discarded.
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+	defer signal.Stop(resized)
+	inputs := make(chan input)
+	done := make(chan struct{})
+	defer close(done)
+	go readInput(in, inputs, done)
+	return s.run(inputs, resized, func() error {
+		width, height, err := term.GetSize(outFd)
+		if err != nil {
+			return err
+		}
+		return s.draw(out, opts.Header, width, height)
+	})
+}
+
+type input struct {
+	b   []byte
+	err error
+}
+
+func readInput(r io.Reader, inputs chan<- input, done <-chan struct{}) {
+	for {
+		buf := make([]byte, 64)
+		n, err := r.Read(buf)
+		select {
+		case inputs <- input{buf[:n], err}:
+		case <-done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *state) run(inputs <-chan input, resized <-chan os.Signal, draw func() error) ([]int, error) {
+	for {
+		err := draw()
+		if err != nil {
+			return nil, err
+		}
+		select {
+		case <-resized:
+		case in := <-inputs:
+			if in.err != nil {
+				return nil, in.err
+			}
+			for _, e := range parseEvents(in.b) {
+				done, err := s.handle(e)
+				if err != nil {
+					return nil, err
+				}
+				if done {
+					return s.chosen(), nil
+				}
+			}
+		}
+	}
+}
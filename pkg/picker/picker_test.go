@@ -0,0 +1,177 @@
+package picker
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEvents(t *testing.T) {
+	var data = []struct {
+		name     string
+		input    string
+		expected []event
+	}{
+		{"text", "wé", []event{{key: keyRune, char: 'w'}, {key: keyRune, char: 'é'}}},
+		{"arrows", "\x1b[A\x1b[B\x1bOA", []event{{key: keyUp}, {key: keyDown}, {key: keyUp}}},
+		{"page up ignored", "\x1b[5~x", []event{{key: keyRune, char: 'x'}}},
+		{"escape", "\x1b", []event{{key: keyCancel}}},
+		{"ctrl-c", "\x03", []event{{key: keyCancel}}},
+		{"alt ignored", "\x1bx", nil},
+		{"editing", "\x7f\x15", []event{{key: keyBackspace}, {key: keyClear}}},
+		{"select and choose", "\t\r", []event{{key: keyToggle}, {key: keyEnter}}},
+		{"ctrl-p ctrl-n", "\x10\x0e", []event{{key: keyUp}, {key: keyDown}}},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			events := parseEvents([]byte(d.input))
+			if !reflect.DeepEqual(events, d.expected) {
+				t.Errorf("events got %+v, want %+v", events, d.expected)
+			}
+		})
+	}
+}
+
+func contains(query string, line string) int {
+	if strings.Contains(line, query) {
+		return 1
+	}
+	return 0
+}
+
+var items = []Item{
+	{Line: "web-1 i-1", Detail: "name: web-1"},
+	{Line: "db-1  i-2", Detail: "name: db-1"},
+	{Line: "web-2 i-3", Detail: "name: web-2"},
+}
+
+func typed(s string) []event {
+	return parseEvents([]byte(s))
+}
+
+func TestHandle(t *testing.T) {
+	var data = []struct {
+		name            string
+		events          []event
+		expectedChosen  []int
+		expectedMatches []int
+		expectedErr     error
+	}{
+		{"enter takes the cursor row", typed("\r"), []int{0}, []int{0, 1, 2}, nil},
+		{"move down", typed("\x1b[B\r"), []int{1}, []int{0, 1, 2}, nil},
+		{"move stops at the end", typed("\x1b[B\x1b[B\x1b[B\x1b[B\r"), []int{2}, []int{0, 1, 2}, nil},
+		{"filter", typed("web\x1b[B\r"), []int{2}, []int{0, 2}, nil},
+		{"backspace widens", typed("webx\x7f\r"), []int{0}, []int{0, 2}, nil},
+		{"clear", typed("db\x15\r"), []int{0}, []int{0, 1, 2}, nil},
+		{"multi select in item order", typed("\x1b[B\x1b[B\t\x1b[A\x1b[A\t\r"), []int{0, 2}, []int{0, 1, 2}, nil},
+		{"selection survives filtering", typed("db\t\x15web\r"), []int{1}, []int{0, 2}, nil},
+		{"toggle twice", typed("\t\x1b[A\t\r"), []int{1}, []int{0, 1, 2}, nil},
+		{"cancel", typed("\x1b"), nil, []int{0, 1, 2}, ErrCancelled},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			s := newState(items, contains)
+			var chosen []int
+			for _, e := range d.events {
+				done, err := s.handle(e)
+				if !errors.Is(err, d.expectedErr) {
+					t.Fatalf("err got %v, want %v", err, d.expectedErr)
+				}
+				if done {
+					if err == nil {
+						chosen = s.chosen()
+					}
+					break
+				}
+			}
+			if !reflect.DeepEqual(chosen, d.expectedChosen) {
+				t.Errorf("chosen got %v, want %v", chosen, d.expectedChosen)
+			}
+			if !reflect.DeepEqual(s.matches, d.expectedMatches) {
+				t.Errorf("matches got %v, want %v", s.matches, d.expectedMatches)
+			}
+		})
+	}
+}
+
+func TestEnterWithoutMatches(t *testing.T) {
+	s := newState(items, contains)
+	for _, e := range typed("zzz\r") {
+		done, _ := s.handle(e)
+		if done {
+			t.Fatal("enter with nothing to choose should not finish")
+		}
+	}
+}
+
+func TestDraw(t *testing.T) {
+	many := make([]Item, 20)
+	for i := range many {
+		many[i] = Item{Line: strings.Repeat("x", 30), Detail: "detail line 1\ndetail line 2"}
+	}
+	many[15].Line = "target"
+	s := newState(many, contains)
+	for i := 0; i < 15; i++ {
+		s.handle(event{key: keyDown})
+	}
+	var buf bytes.Buffer
+	err := s.draw(&buf, "name", 20, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	output := buf.String()
+	lines := strings.Split(output, "\r\n")
+	if len(lines) != 10 {
+		t.Errorf("lines got %d, want 10:\n%s", len(lines), output)
+	}
+	if !strings.Contains(output, "> target") {
+		t.Errorf("output got %q, want the cursor row scrolled into view", output)
+	}
+	if !strings.Contains(output, "detail line 2") {
+		t.Errorf("output got %q, want the preview", output)
+	}
+	if strings.Contains(output, strings.Repeat("x", 30)) {
+		t.Errorf("output got %q, want rows cut to the width", output)
+	}
+}
+
+func TestRunRedrawsOnResize(t *testing.T) {
+	s := newState(items, contains)
+	inputs := make(chan input, 1)
+	resized := make(chan os.Signal, 1)
+	draws := 0
+	chosen, err := s.run(inputs, resized, func() error {
+		draws++
+		if draws == 1 {
+			resized <- os.Interrupt
+		} else {
+			inputs <- input{b: []byte("\r")}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if draws != 2 {
+		t.Errorf("draws got %d, want 2", draws)
+	}
+	if !reflect.DeepEqual(chosen, []int{0}) {
+		t.Errorf("chosen got %v, want [0]", chosen)
+	}
+}
+
+func TestRunReadError(t *testing.T) {
+	s := newState(items, contains)
+	inputs := make(chan input)
+	done := make(chan struct{})
+	defer close(done)
+	go readInput(strings.NewReader(""), inputs, done)
+	_, err := s.run(inputs, nil, func() error { return nil })
+	if err != io.EOF {
+		t.Errorf("err got %v, want EOF", err)
+	}
+}
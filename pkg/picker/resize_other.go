@@ -0,0 +1,9 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !zos
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!zos
+
+package picker
+
+import "os"
+
+// there is no resize signal, the picker redraws at the new size on the next key
+func notifyResize(c chan<- os.Signal) {}
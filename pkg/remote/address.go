@@ -0,0 +1,53 @@
+package remote
+
+import (
+	"fmt"
+	"strings"
+	"utils/aws/pkg/ec2"
+)
+
+var DefaultAddressKinds = []string{"private", "public"}
+
+var addressesByKind = map[string]func(view ec2.View) string{
+	"private":     func(view ec2.View) string { return view.PrivateIP },
+	"public":      func(view ec2.View) string { return view.PublicIP },
+	"private-dns": func(view ec2.View) string { return view.PrivateDNS },
+	"public-dns":  func(view ec2.View) string { return view.PublicDNS },
+}
+
+func ValidateAddressKinds(kinds []string) error {
+	for _, kind := range kinds {
+		if _, ok := addressesByKind[kind]; !ok {
+			return fmt.Errorf("unknown address kind %q, choose from private, public, private-dns or public-dns", kind)
+		}
+	}
+	return nil
+}
+
+// Address is the first address the instance has, trying kinds in order
+func Address(view ec2.View, kinds []string) (string, error) {
+	if len(kinds) == 0 {
+		kinds = DefaultAddressKinds
+	}
+	for _, kind := range kinds {
+		if address := addressesByKind[kind](view); address != "" {
+			return address, nil
+		}
+	}
+	return "", fmt.Errorf("%s has no %s address", view.InstanceID, strings.Join(kinds, " or "))
+}
+
+// SSHArgs are the arguments for ssh, extra arguments follow the destination so
+// may be options or a remote command
+func SSHArgs(user string, key string, address string, extra []string) []string {
+	var args = make([]string, 0, 3+len(extra))
+	if key != "" {
+		args = append(args, "-i", key)
+	}
+	destination := address
+	if user != "" {
+		destination = user + "@" + address
+	}
+	args = append(args, destination)
+	return append(args, extra...)
+}
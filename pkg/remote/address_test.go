@@ -0,0 +1,65 @@
+package remote
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"utils/aws/pkg/ec2"
+)
+
+func TestAddress(t *testing.T) {
+	both := ec2.View{InstanceID: "i-1", PrivateIP: "10.0.0.1", PublicIP: "54.1.2.3", PublicDNS: "ec2-54-1-2-3.compute-1.amazonaws.com"}
+	privateOnly := ec2.View{InstanceID: "i-2", PrivateIP: "10.0.0.2"}
+	var data = []struct {
+		testName string
+		view     ec2.View
+		kinds    []string
+		expected string
+		err      string
+	}{
+		{"default prefers private", both, nil, "10.0.0.1", ""},
+		{"public first", both, []string{"public", "private"}, "54.1.2.3", ""},
+		{"public dns", both, []string{"public-dns"}, "ec2-54-1-2-3.compute-1.amazonaws.com", ""},
+		{"falls back", privateOnly, []string{"public", "private"}, "10.0.0.2", ""},
+		{"no address", privateOnly, []string{"public", "public-dns"}, "", "i-2 has no public or public-dns address"},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			address, err := Address(d.view, d.kinds)
+			if d.err != "" {
+				if err == nil || !strings.Contains(err.Error(), d.err) {
+					t.Fatalf("err got %v, want %q", err, d.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if address != d.expected {
+				t.Errorf("address got %q, want %q", address, d.expected)
+			}
+		})
+	}
+}
+
+func TestSSHArgs(t *testing.T) {
+	var data = []struct {
+		testName string
+		user     string
+		key      string
+		extra    []string
+		expected []string
+	}{
+		{"address only", "", "", nil, []string{"10.0.0.1"}},
+		{"user and key", "ubuntu", "k.pem", nil, []string{"-i", "k.pem", "ubuntu@10.0.0.1"}},
+		{"extra args", "ubuntu", "", []string{"-L", "8080:localhost:80"}, []string{"ubuntu@10.0.0.1", "-L", "8080:localhost:80"}},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			args := SSHArgs(d.user, d.key, "10.0.0.1", d.extra)
+			if !reflect.DeepEqual(args, d.expected) {
+				t.Errorf("args got %v, want %v", args, d.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package remote
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"utils/aws/pkg/ec2"
+)
+
+// Config says how to connect to instances, read from a json file such as
+//
+//	{
+//	  "address": ["private", "public"],
+//	  "user": "ec2-user",
+//	  "rules": [
+//	    {"tag": "team=data", "user": "ubuntu", "key": "~/.ssh/data.pem"},
+//	    {"ami": "ami-0123456789abcdef0", "user": "admin"}
+//	  ]
+//	}
+type Config struct {
+	Address []string `json:"address"`
+	User    string   `json:"user"`
+	Key     string   `json:"key"`
+	Rules   []Rule   `json:"rules"`
+}
+
+// Rule applies its user and key to instances with the tag, key=value or key=
+// for any value, and the ami when they are set
+type Rule struct {
+	Tag  string `json:"tag"`
+	AMI  string `json:"ami"`
+	User string `json:"user"`
+	Key  string `json:"key"`
+}
+
+func (r Rule) Matches(view ec2.View) bool {
+	if r.AMI != "" && r.AMI != view.ImageID {
+		return false
+	}
+	if r.Tag != "" {
+		i := strings.Index(r.Tag, "=")
+		if i < 0 {
+			return false
+		}
+		value, ok := view.Tags[r.Tag[:i]]
+		if !ok || (r.Tag[i+1:] != "" && r.Tag[i+1:] != value) {
+			return false
+		}
+	}
+	return r.Tag != "" || r.AMI != ""
+}
+
+// Login is the user and key for the instance, from the first matching rule
+// with the top level settings filling any gaps
+func (c Config) Login(view ec2.View) (user string, key string) {
+	user, key = c.User, c.Key
+	for _, rule := range c.Rules {
+		if rule.Matches(view) {
+			if rule.User != "" {
+				user = rule.User
+			}
+			if rule.Key != "" {
+				key = rule.Key
+			}
+			break
+		}
+	}
+	return user, expandHome(key)
+}
+
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
+
+func ReadConfig(r io.Reader) (Config, error) {
+	var c Config
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&c)
+	if err != nil {
+		return c, err
+	}
+	err = ValidateAddressKinds(c.Address)
+	if err != nil {
+		return c, err
+	}
+	for i, rule := range c.Rules {
+		if rule.Tag == "" && rule.AMI == "" {
+			return c, fmt.Errorf("rule %d has neither tag nor ami", i+1)
+		}
+		if rule.Tag != "" && strings.Index(rule.Tag, "=") <= 0 {
+			return c, fmt.Errorf("rule %d tag %q should be key=value or key=", i+1, rule.Tag)
+		}
+	}
+	return c, nil
+}
+
+// DefaultConfigPath is $AWSI_CONFIG, or awsi/config.json in the user config directory
+func DefaultConfigPath() string {
+	if path := os.Getenv("AWSI_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "awsi", "config.json")
+}
+
+// LoadConfig reads the config at path, a missing file is an empty config
+func LoadConfig(path string) (Config, error) {
+	if path == "" {
+		return Config{}, nil
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, err
+	}
+	defer f.Close()
+	c, err := ReadConfig(f)
+	if err != nil {
+		return c, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
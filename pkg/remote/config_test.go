@@ -0,0 +1,84 @@
+package remote
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"utils/aws/pkg/ec2"
+)
+
+func TestReadConfig(t *testing.T) {
+	var data = []struct {
+		testName string
+		json     string
+		err      string
+	}{
+		{"valid", `{"address": ["public-dns"], "user": "ec2-user", "rules": [{"tag": "team=data", "user": "ubuntu"}, {"ami": "ami-1", "key": "k.pem"}]}`, ""},
+		{"empty", `{}`, ""},
+		{"unknown field", `{"usr": "ec2-user"}`, "unknown field \"usr\""},
+		{"unknown address", `{"address": ["elastic"]}`, "unknown address kind \"elastic\""},
+		{"empty rule", `{"rules": [{"user": "ubuntu"}]}`, "rule 1 has neither tag nor ami"},
+		{"bad tag", `{"rules": [{"tag": "team", "user": "ubuntu"}]}`, "rule 1 tag \"team\" should be key=value or key="},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			_, err := ReadConfig(strings.NewReader(d.json))
+			if d.err == "" && err != nil {
+				t.Fatalf("err got %v, want nil", err)
+			}
+			if d.err != "" && (err == nil || !strings.Contains(err.Error(), d.err)) {
+				t.Errorf("err got %v, want %q", err, d.err)
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	config := Config{User: "ec2-user", Key: "default.pem", Rules: []Rule{
+		{Tag: "team=data", User: "ubuntu", Key: "~/.ssh/data.pem"},
+		{Tag: "bastion=", User: "admin"},
+		{AMI: "ami-1", Tag: "team=web", Key: "web.pem"},
+	}}
+	var data = []struct {
+		testName     string
+		view         ec2.View
+		expectedUser string
+		expectedKey  string
+	}{
+		{"defaults", ec2.View{Tags: map[string]string{}}, "ec2-user", "default.pem"},
+		{"tag value", ec2.View{Tags: map[string]string{"team": "data"}}, "ubuntu", filepath.Join(home, ".ssh/data.pem")},
+		{"tag present", ec2.View{Tags: map[string]string{"bastion": "yes"}}, "admin", "default.pem"},
+		{"ami and tag", ec2.View{ImageID: "ami-1", Tags: map[string]string{"team": "web"}}, "ec2-user", "web.pem"},
+		{"ami without tag", ec2.View{ImageID: "ami-1", Tags: map[string]string{}}, "ec2-user", "default.pem"},
+		{"first rule wins", ec2.View{Tags: map[string]string{"team": "data", "bastion": ""}}, "ubuntu", filepath.Join(home, ".ssh/data.pem")},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			user, key := config.Login(d.view)
+			if user != d.expectedUser || key != d.expectedKey {
+				t.Errorf("login got %s %s, want %s %s", user, key, d.expectedUser, d.expectedKey)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	config, err := LoadConfig(filepath.Join(dir, "missing.json"))
+	if err != nil || config.User != "" {
+		t.Errorf("missing config got %+v %v, want empty", config, err)
+	}
+	path := filepath.Join(dir, "config.json")
+	_ = os.WriteFile(path, []byte(`{"user": "ubuntu"}`), 0600)
+	config, err = LoadConfig(path)
+	if err != nil || config.User != "ubuntu" {
+		t.Errorf("config got %+v %v, want user ubuntu", config, err)
+	}
+	_ = os.WriteFile(path, []byte(`{"user": `), 0600)
+	_, err = LoadConfig(path)
+	if err == nil || !strings.HasPrefix(err.Error(), path) {
+		t.Errorf("err got %v, want it to name the file", err)
+	}
+}
@@ -0,0 +1,171 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// SessionManagerPlugin is the program the aws cli hands sessions to
+const SessionManagerPlugin = "session-manager-plugin"
+
+type sessionClient interface {
+	DescribeInstanceInformation(ctx context.Context, params *ssm.DescribeInstanceInformationInput, optFns ...func(*ssm.Options)) (*ssm.DescribeInstanceInformationOutput, error)
+	StartSession(ctx context.Context, params *ssm.StartSessionInput, optFns ...func(*ssm.Options)) (*ssm.StartSessionOutput, error)
+}
+
+// Forward is a port forwarding session, local port to a port on the instance
+// or on a host reachable from it
+type Forward struct {
+	LocalPort  int
+	Host       string
+	RemotePort int
+}
+
+// ParseForward reads local:host:remote, or local:remote for a port on the instance
+func ParseForward(s string) (Forward, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) == 2 {
+		parts = []string{parts[0], "localhost", parts[1]}
+	}
+	if len(parts) != 3 || parts[1] == "" {
+		return Forward{}, fmt.Errorf("invalid forward %q, expected local-port:host:remote-port", s)
+	}
+	local, err := parsePort(parts[0])
+	if err != nil {
+		return Forward{}, err
+	}
+	remote, err := parsePort(parts[2])
+	if err != nil {
+		return Forward{}, err
+	}
+	return Forward{LocalPort: local, Host: parts[1], RemotePort: remote}, nil
+}
+
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+	return port, nil
+}
+
+// SessionInput is a shell session, or a port forwarding session when forward is set
+func SessionInput(instanceID string, forward *Forward) *ssm.StartSessionInput {
+	input := &ssm.StartSessionInput{Target: aws.String(instanceID)}
+	if forward == nil {
+		return input
+	}
+	input.Parameters = map[string][]string{
+		"portNumber":      {strconv.Itoa(forward.RemotePort)},
+		"localPortNumber": {strconv.Itoa(forward.LocalPort)},
+	}
+	if forward.Host == "localhost" {
+		input.DocumentName = aws.String("AWS-StartPortForwardingSession")
+	} else {
+		input.DocumentName = aws.String("AWS-StartPortForwardingSessionToRemoteHost")
+		input.Parameters["host"] = []string{forward.Host}
+	}
+	return input
+}
+
+// Session is a started session and what the plugin needs to connect to it
+type Session struct {
+	Output   *ssm.StartSessionOutput
+	Input    *ssm.StartSessionInput
+	Region   string
+	Endpoint string
+}
+
+// StartSession checks the instance's ssm agent is online then starts a session
+func StartSession(ctx context.Context, cfg aws.Config, input *ssm.StartSessionInput) (Session, error) {
+	endpoint, err := resolveEndpoint(cfg)
+	if err != nil {
+		return Session{}, err
+	}
+	return startSession(ctx, ssm.NewFromConfig(cfg), cfg.Region, endpoint, input)
+}
+
+// resolveEndpoint gives the plugin the same ssm endpoint the client uses, so
+// custom endpoints and partitions other than aws (aws-cn, aws-us-gov) work
+func resolveEndpoint(cfg aws.Config) (string, error) {
+	if cfg.EndpointResolver != nil {
+		endpoint, err := cfg.EndpointResolver.ResolveEndpoint(ssm.ServiceID, cfg.Region)
+		if err == nil {
+			return endpoint.URL, nil
+		}
+		var notFound *aws.EndpointNotFoundError
+		if !errors.As(err, &notFound) {
+			return "", err
+		}
+	}
+	endpoint, err := ssm.NewDefaultEndpointResolver().ResolveEndpoint(cfg.Region, ssm.EndpointResolverOptions{})
+	if err != nil {
+		return "", err
+	}
+	return endpoint.URL, nil
+}
+
+func startSession(ctx context.Context, client sessionClient, region string, endpoint string, input *ssm.StartSessionInput) (Session, error) {
+	err := checkAgent(ctx, client, *input.Target)
+	if err != nil {
+		return Session{}, err
+	}
+	output, err := client.StartSession(ctx, input)
+	if err != nil {
+		return Session{}, err
+	}
+	return Session{
+		Output:   output,
+		Input:    input,
+		Region:   region,
+		Endpoint: endpoint,
+	}, nil
+}
+
+func checkAgent(ctx context.Context, client sessionClient, instanceID string) error {
+	output, err := client.DescribeInstanceInformation(ctx, &ssm.DescribeInstanceInformationInput{
+		Filters: []types.InstanceInformationStringFilter{{Key: aws.String("InstanceIds"), Values: []string{instanceID}}},
+	})
+	if err != nil {
+		return err
+	}
+	for _, info := range output.InstanceInformationList {
+		if aws.ToString(info.InstanceId) != instanceID {
+			continue
+		}
+		if info.PingStatus != types.PingStatusOnline {
+			return fmt.Errorf("ssm agent on %s is %s", instanceID, info.PingStatus)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s is not registered with ssm", instanceID)
+}
+
+// PluginArgs are the arguments the aws cli passes to the session manager plugin
+func (s Session) PluginArgs(profile string) ([]string, error) {
+	response, err := json.Marshal(struct {
+		SessionId  string
+		StreamUrl  string
+		TokenValue string
+	}{aws.ToString(s.Output.SessionId), aws.ToString(s.Output.StreamUrl), aws.ToString(s.Output.TokenValue)})
+	if err != nil {
+		return nil, err
+	}
+	request, err := json.Marshal(struct {
+		Target       string
+		DocumentName string              `json:",omitempty"`
+		Parameters   map[string][]string `json:",omitempty"`
+	}{aws.ToString(s.Input.Target), aws.ToString(s.Input.DocumentName), s.Input.Parameters})
+	if err != nil {
+		return nil, err
+	}
+	return []string{string(response), s.Region, "StartSession", profile, string(request), s.Endpoint}, nil
+}
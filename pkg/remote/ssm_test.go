@@ -0,0 +1,196 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+func TestParseForward(t *testing.T) {
+	var data = []struct {
+		input    string
+		expected Forward
+		err      string
+	}{
+		{"5432:localhost:5432", Forward{5432, "localhost", 5432}, ""},
+		{"15432:db.internal:5432", Forward{15432, "db.internal", 5432}, ""},
+		{"8080:80", Forward{8080, "localhost", 80}, ""},
+		{"8080", Forward{}, `invalid forward "8080", expected local-port:host:remote-port`},
+		{"8080::80", Forward{}, `invalid forward "8080::80", expected local-port:host:remote-port`},
+		{"x:localhost:80", Forward{}, `invalid port "x"`},
+		{"8080:localhost:70000", Forward{}, `invalid port "70000"`},
+	}
+	for _, d := range data {
+		t.Run(d.input, func(t *testing.T) {
+			forward, err := ParseForward(d.input)
+			if d.err != "" {
+				if err == nil || err.Error() != d.err {
+					t.Fatalf("err got %v, want %q", err, d.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if forward != d.expected {
+				t.Errorf("forward got %+v, want %+v", forward, d.expected)
+			}
+		})
+	}
+}
+
+func TestSessionInput(t *testing.T) {
+	var data = []struct {
+		testName           string
+		forward            *Forward
+		expectedDocument   *string
+		expectedParameters map[string][]string
+	}{
+		{"shell", nil, nil, nil},
+		{"instance port", &Forward{5432, "localhost", 5432}, aws.String("AWS-StartPortForwardingSession"),
+			map[string][]string{"portNumber": {"5432"}, "localPortNumber": {"5432"}}},
+		{"remote host", &Forward{15432, "db.internal", 5432}, aws.String("AWS-StartPortForwardingSessionToRemoteHost"),
+			map[string][]string{"portNumber": {"5432"}, "localPortNumber": {"15432"}, "host": {"db.internal"}}},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			input := SessionInput("i-1", d.forward)
+			if aws.ToString(input.Target) != "i-1" {
+				t.Errorf("Target got %v, want i-1", aws.ToString(input.Target))
+			}
+			if !reflect.DeepEqual(input.DocumentName, d.expectedDocument) {
+				t.Errorf("DocumentName got %v, want %v", aws.ToString(input.DocumentName), aws.ToString(d.expectedDocument))
+			}
+			if !reflect.DeepEqual(input.Parameters, d.expectedParameters) {
+				t.Errorf("Parameters got %v, want %v", input.Parameters, d.expectedParameters)
+			}
+		})
+	}
+}
+
+type sessionClientMock struct {
+	instances []types.InstanceInformation
+	err       error
+	started   *ssm.StartSessionInput
+}
+
+func (m *sessionClientMock) DescribeInstanceInformation(ctx context.Context, params *ssm.DescribeInstanceInformationInput, optFns ...func(*ssm.Options)) (*ssm.DescribeInstanceInformationOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &ssm.DescribeInstanceInformationOutput{InstanceInformationList: m.instances}, nil
+}
+
+func (m *sessionClientMock) StartSession(ctx context.Context, params *ssm.StartSessionInput, optFns ...func(*ssm.Options)) (*ssm.StartSessionOutput, error) {
+	m.started = params
+	return &ssm.StartSessionOutput{SessionId: aws.String("s-1"), StreamUrl: aws.String("wss://stream"), TokenValue: aws.String("token")}, nil
+}
+
+func TestStartSession(t *testing.T) {
+	online := types.InstanceInformation{InstanceId: aws.String("i-1"), PingStatus: types.PingStatusOnline}
+	lost := types.InstanceInformation{InstanceId: aws.String("i-1"), PingStatus: types.PingStatusConnectionLost}
+	var data = []struct {
+		testName string
+		client   *sessionClientMock
+		err      string
+	}{
+		{"online", &sessionClientMock{instances: []types.InstanceInformation{online}}, ""},
+		{"connection lost", &sessionClientMock{instances: []types.InstanceInformation{lost}}, "ssm agent on i-1 is ConnectionLost"},
+		{"not registered", &sessionClientMock{}, "i-1 is not registered with ssm"},
+		{"describe fails", &sessionClientMock{err: errors.New("denied")}, "denied"},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			session, err := startSession(context.Background(), d.client, "eu-west-1", "https://ssm.eu-west-1.amazonaws.com", SessionInput("i-1", nil))
+			if d.err != "" {
+				if err == nil || err.Error() != d.err {
+					t.Fatalf("err got %v, want %q", err, d.err)
+				}
+				if d.client.started != nil {
+					t.Errorf("session started despite error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if session.Endpoint != "https://ssm.eu-west-1.amazonaws.com" {
+				t.Errorf("Endpoint got %s, want https://ssm.eu-west-1.amazonaws.com", session.Endpoint)
+			}
+		})
+	}
+}
+
+func TestResolveEndpoint(t *testing.T) {
+	custom := aws.EndpointResolverFunc(func(service, region string) (aws.Endpoint, error) {
+		if service == ssm.ServiceID {
+			return aws.Endpoint{URL: "https://vpce-1.ssm.eu-west-1.vpce.amazonaws.com"}, nil
+		}
+		return aws.Endpoint{}, &aws.EndpointNotFoundError{}
+	})
+	other := aws.EndpointResolverFunc(func(service, region string) (aws.Endpoint, error) {
+		return aws.Endpoint{}, &aws.EndpointNotFoundError{}
+	})
+	failing := aws.EndpointResolverFunc(func(service, region string) (aws.Endpoint, error) {
+		return aws.Endpoint{}, errors.New("broken")
+	})
+	var data = []struct {
+		testName string
+		cfg      aws.Config
+		expected string
+		err      string
+	}{
+		{"aws", aws.Config{Region: "eu-west-1"}, "https://ssm.eu-west-1.amazonaws.com", ""},
+		{"china", aws.Config{Region: "cn-north-1"}, "https://ssm.cn-north-1.amazonaws.com.cn", ""},
+		{"govcloud", aws.Config{Region: "us-gov-west-1"}, "https://ssm.us-gov-west-1.amazonaws.com", ""},
+		{"custom resolver", aws.Config{Region: "eu-west-1", EndpointResolver: custom}, "https://vpce-1.ssm.eu-west-1.vpce.amazonaws.com", ""},
+		{"custom resolver for other services", aws.Config{Region: "cn-north-1", EndpointResolver: other}, "https://ssm.cn-north-1.amazonaws.com.cn", ""},
+		{"custom resolver fails", aws.Config{Region: "eu-west-1", EndpointResolver: failing}, "", "broken"},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			endpoint, err := resolveEndpoint(d.cfg)
+			if d.err != "" {
+				if err == nil || err.Error() != d.err {
+					t.Fatalf("err got %v, want %q", err, d.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if endpoint != d.expected {
+				t.Errorf("endpoint got %s, want %s", endpoint, d.expected)
+			}
+		})
+	}
+}
+
+func TestPluginArgs(t *testing.T) {
+	session := Session{
+		Output:   &ssm.StartSessionOutput{SessionId: aws.String("s-1"), StreamUrl: aws.String("wss://stream"), TokenValue: aws.String("token")},
+		Input:    SessionInput("i-1", &Forward{8080, "localhost", 80}),
+		Region:   "eu-west-1",
+		Endpoint: "https://ssm.eu-west-1.amazonaws.com",
+	}
+	args, err := session.PluginArgs("dev")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{
+		`{"SessionId":"s-1","StreamUrl":"wss://stream","TokenValue":"token"}`,
+		"eu-west-1",
+		"StartSession",
+		"dev",
+		`{"Target":"i-1","DocumentName":"AWS-StartPortForwardingSession","Parameters":{"localPortNumber":["8080"],"portNumber":["80"]}}`,
+		"https://ssm.eu-west-1.amazonaws.com",
+	}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("args got %q, want %q", args, expected)
+	}
+}
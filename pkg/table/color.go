@@ -0,0 +1,121 @@
+package table
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	Reset     = "\x1b[0m"
+	Bold      = "\x1b[1m"
+	Green     = "\x1b[32m"
+	Yellow    = "\x1b[33m"
+	Grey      = "\x1b[90m"
+	Highlight = "\x1b[1;31m"
+)
+
+// Styler decorates cells of the fixed width table, typically with ansi colours
+type Styler interface {
+	Header(heading string) string
+	Cell(column string, value string) string
+}
+
+func Colorize(code string, s string) string {
+	if s == "" {
+		return s
+	}
+	return code + s + Reset
+}
+
+// VisibleWidth is the number of terminal columns s takes up, skipping ansi
+// escape sequences and counting wide characters such as CJK and emoji as two
+func VisibleWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if n := escapeLength(s, i); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// escapeLength is the length of the ansi escape sequence starting at s[i], 0 if there is none
+func escapeLength(s string, i int) int {
+	if s[i] != '\x1b' || i+1 >= len(s) || s[i+1] != '[' {
+		return 0
+	}
+	end := i + 2
+	for end < len(s) && (s[end] < 0x40 || s[end] > 0x7e) {
+		end++
+	}
+	if end < len(s) {
+		end++
+	}
+	return end - i
+}
+
+// east asian wide and emoji ranges, a subset of what wcwidth covers
+var wideRanges = []struct{ first, last rune }{
+	{0x1100, 0x115f},
+	{0x2e80, 0x303e},
+	{0x3041, 0x33ff},
+	{0x3400, 0x4dbf},
+	{0x4e00, 0x9fff},
+	{0xa000, 0xa4cf},
+	{0xac00, 0xd7a3},
+	{0xf900, 0xfaff},
+	{0xfe30, 0xfe4f},
+	{0xff00, 0xff60},
+	{0xffe0, 0xffe6},
+	{0x1f300, 0x1f64f},
+	{0x1f680, 0x1f6ff},
+	{0x1f900, 0x1f9ff},
+	{0x20000, 0x3fffd},
+}
+
+func runeWidth(r rune) int {
+	// combining marks, zero width joiners and variation selectors attach to the previous character
+	if unicode.In(r, unicode.Mn, unicode.Me) || r == 0x200b || r == 0x200d || (r >= 0xfe00 && r <= 0xfe0f) {
+		return 0
+	}
+	for _, wide := range wideRanges {
+		if r >= wide.first && r <= wide.last {
+			return 2
+		}
+	}
+	return 1
+}
+
+// Truncate shortens s to fit width columns, ending with an ellipsis when cut.
+// Escape sequences take no room, a cut styled string ends with a reset.
+func Truncate(s string, width int) string {
+	if VisibleWidth(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+	used := 0
+	styled := false
+	for i := 0; i < len(s); {
+		if n := escapeLength(s, i); n > 0 {
+			styled = true
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if used+runeWidth(r) > width-1 {
+			if styled {
+				return s[:i] + "…" + Reset
+			}
+			return s[:i] + "…"
+		}
+		used += runeWidth(r)
+		i += size
+	}
+	return s
+}
@@ -0,0 +1,100 @@
+package table
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestVisibleWidth(t *testing.T) {
+	var data = []struct {
+		s        string
+		expected int
+	}{
+		{"", 0},
+		{"running", 7},
+		{Colorize(Green, "running"), 7},
+		{"web-" + Colorize(Highlight, "prod") + "-1", 10},
+		{"café", 4},
+		{"\x1b[1;31", 0},
+		{"東京-web", 8},
+		{"web-🚀", 6},
+		{"e\u0301", 1},
+	}
+	for _, d := range data {
+		t.Run(d.s, func(t *testing.T) {
+			if width := VisibleWidth(d.s); width != d.expected {
+				t.Errorf("width got %d, want %d", width, d.expected)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	var data = []struct {
+		s        string
+		width    int
+		expected string
+	}{
+		{"web-prod-1", 20, "web-prod-1"},
+		{"web-prod-1", 10, "web-prod-1"},
+		{"web-prod-1", 6, "web-p…"},
+		{"東京東京", 5, "東京…"},
+		{"東京東京", 4, "東…"},
+		{"web", 0, ""},
+		{"\x1b[32mweb-prod-1\x1b[0m", 10, "\x1b[32mweb-prod-1\x1b[0m"},
+		{"\x1b[32mweb-prod-1\x1b[0m", 6, "\x1b[32mweb-p…\x1b[0m"},
+		{"web-\x1b[1;31mprod\x1b[0m-1", 6, "web-\x1b[1;31mp…\x1b[0m"},
+	}
+	for _, d := range data {
+		t.Run(d.s, func(t *testing.T) {
+			truncated := Truncate(d.s, d.width)
+			if truncated != d.expected {
+				t.Errorf("truncated got %q, want %q", truncated, d.expected)
+			}
+			if VisibleWidth(truncated) > d.width {
+				t.Errorf("width got %d, want at most %d", VisibleWidth(truncated), d.width)
+			}
+		})
+	}
+}
+
+type testStyler struct{}
+
+func (testStyler) Header(heading string) string {
+	return Colorize(Bold, heading)
+}
+
+func (testStyler) Cell(column string, value string) string {
+	if column == "heading2" || value == "running-long" {
+		return Colorize(Green, value)
+	}
+	return value
+}
+
+func TestRenderStyled(t *testing.T) {
+	var buf bytes.Buffer
+	err := createTestTable().Render(&buf, fixedWidth{}, Options{Header: true, Styler: testStyler{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "\x1b[1ma\x1b[0m    \x1b[1mheading2\x1b[0m \x1b[1m3\x1b[0m\n\n" +
+		"r1c1 \x1b[32mmore\x1b[0m     1\n" +
+		"r2c1 \x1b[32mcellr2\x1b[0m   2\n"
+	if buf.String() != expected {
+		t.Errorf("output got %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestRenderStyledBeforeCut(t *testing.T) {
+	stateTable := New([]string{"state"})
+	_ = stateTable.AddRow([]string{"running-long"}, []Tag{})
+	var buf bytes.Buffer
+	err := stateTable.Render(&buf, fixedWidth{}, Options{Styler: testStyler{}, MaxWidth: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "\x1b[32mrunning…\x1b[0m\n"
+	if buf.String() != expected {
+		t.Errorf("output got %q, want %q", buf.String(), expected)
+	}
+}
@@ -0,0 +1,114 @@
+package table
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"sort"
+	"strings"
+)
+
+func init() {
+	Register("csv", delimited{comma: ','})
+	Register("tsv", delimited{comma: '\t'})
+}
+
+// delimited writes rows as csv with the given separator, quoting cells as
+// needed, or as tsv with tabs, newlines and backslashes escaped instead.
+// opts.Tags adds a column per tag key found on any row.
+type delimited struct {
+	comma rune
+}
+
+type recordWriter interface {
+	Write(record []string) error
+	Flush()
+	Error() error
+}
+
+func (d delimited) writer(w io.Writer) recordWriter {
+	if d.comma == '\t' {
+		return &tsvWriter{w: bufio.NewWriter(w)}
+	}
+	out := csv.NewWriter(w)
+	out.Comma = d.comma
+	return out
+}
+
+func (d delimited) Render(w io.Writer, header []string, rows [][]string, tags [][]Tag, opts Options) error {
+	var keys []string
+	if opts.Tags {
+		keys = tagKeys(tags)
+	}
+	out := d.writer(w)
+	if opts.Header {
+		err := out.Write(append(append([]string{}, header...), keys...))
+		if err != nil {
+			return err
+		}
+	}
+	for i, row := range rows {
+		record := append([]string{}, row...)
+		for _, key := range keys {
+			record = append(record, tagValue(tags[i], key))
+		}
+		err := out.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+var tsvEscaper = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+// tsvWriter follows the text/tab-separated-values convention of escaping
+// separators inside cells rather than quoting them
+type tsvWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (t *tsvWriter) Write(record []string) error {
+	for i, cell := range record {
+		if i > 0 {
+			t.w.WriteByte('\t')
+		}
+		t.w.WriteString(tsvEscaper.Replace(cell))
+	}
+	_, err := t.w.WriteString("\n")
+	return err
+}
+
+func (t *tsvWriter) Flush() {
+	t.err = t.w.Flush()
+}
+
+func (t *tsvWriter) Error() error {
+	return t.err
+}
+
+func tagKeys(tags [][]Tag) []string {
+	seen := make(map[string]bool)
+	keys := make([]string, 0)
+	for _, rowTags := range tags {
+		for _, tag := range rowTags {
+			if !seen[tag.Key] {
+				seen[tag.Key] = true
+				keys = append(keys, tag.Key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func tagValue(tags []Tag, key string) string {
+	for _, tag := range tags {
+		if tag.Key == key {
+			return tag.Value
+		}
+	}
+	return ""
+}
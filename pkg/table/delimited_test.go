@@ -0,0 +1,51 @@
+package table
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintDelimited(t *testing.T) {
+	var data = []struct {
+		testName   string
+		comma      rune
+		withHeader bool
+		withTags   bool
+		expected   string
+	}{
+		{"csv", ',', true, false, "name,id\n\"web, \"\"1\"\"\",i-1\ndb,i-2\n"},
+		{"csv without header", ',', false, false, "\"web, \"\"1\"\"\",i-1\ndb,i-2\n"},
+		{"csv with tags", ',', true, true, "name,id,Name,Owner\n\"web, \"\"1\"\"\",i-1,web,alice\ndb,i-2,,\n"},
+		{"tsv", '\t', true, false, "name\tid\nweb, \"1\"\ti-1\ndb\ti-2\n"},
+		{"tsv with tags", '\t', true, true, "name\tid\tName\tOwner\nweb, \"1\"\ti-1\tweb\talice\ndb\ti-2\t\t\n"},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			delimitedTable := New([]string{"name", "id"})
+			_ = delimitedTable.AddRow([]string{"web, \"1\"", "i-1"}, []Tag{{"Owner", "alice"}, {"Name", "web"}})
+			_ = delimitedTable.AddRow([]string{"db", "i-2"}, []Tag{})
+			var buf bytes.Buffer
+			err := delimitedTable.Render(&buf, delimited{comma: d.comma}, Options{Header: d.withHeader, Tags: d.withTags})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != d.expected {
+				t.Errorf("output got %q, want %q", buf.String(), d.expected)
+			}
+		})
+	}
+}
+
+func TestPrintTSVEscapes(t *testing.T) {
+	tsvTable := New([]string{"name", "id"})
+	_ = tsvTable.AddRow([]string{"web\t1\nnew\\line", "i-1"}, []Tag{})
+	var buf bytes.Buffer
+	err := tsvTable.Render(&buf, delimited{comma: '\t'}, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "web\\t1\\nnew\\\\line\ti-1\n"
+	if buf.String() != expected {
+		t.Errorf("output got %q, want %q", buf.String(), expected)
+	}
+}
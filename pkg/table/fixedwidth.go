@@ -0,0 +1,142 @@
+package table
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func init() {
+	Register("table", fixedWidth{})
+}
+
+type fixedWidth struct{}
+
+func (fixedWidth) Render(w io.Writer, header []string, rows [][]string, tags [][]Tag, opts Options) error {
+	widths := append([]int{}, opts.widths...)
+	if opts.widths == nil {
+		widths = columnWidths(header, rows)
+	}
+	if opts.MaxWidth > 0 {
+		fitWidths(widths, opts.MaxWidth, keptColumns(header, opts.Keep))
+	}
+	maxTagKeyLength := 0
+	for _, rowTags := range tags {
+		for _, tag := range rowTags {
+			if VisibleWidth(tag.Key) > maxTagKeyLength {
+				maxTagKeyLength = VisibleWidth(tag.Key)
+			}
+		}
+	}
+	styleHeader := func(i int, heading string) string { return heading }
+	styleCell := func(i int, cell string) string { return cell }
+	if opts.Styler != nil {
+		styleHeader = func(i int, heading string) string { return opts.Styler.Header(heading) }
+		styleCell = func(i int, cell string) string { return opts.Styler.Cell(header[i], cell) }
+	}
+	ew := &errWriter{w: w}
+	if opts.Header {
+		printRow(ew, widths, header, styleHeader)
+		fmt.Fprintln(ew)
+	}
+	for i, row := range rows {
+		printRow(ew, widths, row, styleCell)
+		if opts.Tags {
+			printTags(ew, maxTagKeyLength, tags[i], i+1 == len(rows))
+		}
+	}
+	return ew.err
+}
+
+func columnWidths(header []string, rows [][]string) []int {
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			if VisibleWidth(cell) > widths[i] {
+				widths[i] = VisibleWidth(cell)
+			}
+		}
+	}
+	return widths
+}
+
+func keptColumns(header []string, keep []string) []bool {
+	kept := make([]bool, len(header))
+	for i, heading := range header {
+		for _, k := range keep {
+			if heading == k {
+				kept[i] = true
+			}
+		}
+	}
+	return kept
+}
+
+// columns are not cut below this unless they are already narrower
+const minColumnWidth = 6
+
+// fitWidths narrows the widest column one at a time until the row, with the
+// spaces between columns, fits in maxWidth or nothing more can be cut. Kept
+// columns are only cut when no other column can be.
+func fitWidths(widths []int, maxWidth int, kept []bool) {
+	total := len(widths) - 1
+	for _, width := range widths {
+		total += width
+	}
+	for total > maxWidth {
+		widest := -1
+		for i, width := range widths {
+			if width <= minColumnWidth {
+				continue
+			}
+			if widest < 0 || (kept[widest] && !kept[i]) || (kept[widest] == kept[i] && width > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
+
+// cells are styled before they are cut so the styler sees whole values,
+// padding is worked out from the visible width so escape codes do not count
+func printRow(w io.Writer, widths []int, row []string, style func(i int, cell string) string) {
+	for i, cell := range row {
+		cell = Truncate(style(i, cell), widths[i])
+		fmt.Fprint(w, cell)
+		if padding := widths[i] - VisibleWidth(cell); padding > 0 {
+			fmt.Fprint(w, strings.Repeat(" ", padding))
+		}
+		if i+1 < len(row) {
+			fmt.Fprint(w, " ")
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+func printTags(w io.Writer, keyWidth int, tags []Tag, isLastRow bool) {
+	for _, tag := range tags {
+		fmt.Fprintf(w, "%s%s %s\n", strings.Repeat(" ", keyWidth-VisibleWidth(tag.Key)), tag.Key, tag.Value)
+	}
+	if !isLastRow {
+		fmt.Fprintln(w)
+	}
+}
+
+// errWriter keeps the first write error so the fmt calls above need no checks
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	n, err := ew.w.Write(p)
+	ew.err = err
+	return n, err
+}
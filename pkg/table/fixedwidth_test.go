@@ -0,0 +1,70 @@
+package table
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFitWidths(t *testing.T) {
+	var data = []struct {
+		testName string
+		widths   []int
+		maxWidth int
+		kept     []bool
+		expected []int
+	}{
+		{"fits", []int{4, 8, 1}, 20, []bool{false, false, false}, []int{4, 8, 1}},
+		{"widest is cut", []int{4, 20, 10}, 30, []bool{false, false, false}, []int{4, 14, 10}},
+		{"several columns are cut", []int{4, 20, 18}, 30, []bool{false, false, false}, []int{4, 12, 12}},
+		{"not below the minimum", []int{4, 20, 10}, 10, []bool{false, false, false}, []int{4, 6, 6}},
+		{"kept column is cut last", []int{4, 20, 10}, 30, []bool{false, true, false}, []int{4, 18, 6}},
+		{"kept column is cut when nothing else can be", []int{4, 20, 10}, 10, []bool{false, true, false}, []int{4, 6, 6}},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			widths := append([]int{}, d.widths...)
+			fitWidths(widths, d.maxWidth, d.kept)
+			if !reflect.DeepEqual(widths, d.expected) {
+				t.Errorf("widths got %v, want %v", widths, d.expected)
+			}
+		})
+	}
+}
+
+func TestRenderMaxWidth(t *testing.T) {
+	wideTable := New([]string{"name", "id"})
+	_ = wideTable.AddRow([]string{"web-production-frontend-1", "i-0123456789abcdef0"}, []Tag{})
+	_ = wideTable.AddRow([]string{"東京-web", "i-1"}, []Tag{})
+	var buf bytes.Buffer
+	err := wideTable.Render(&buf, fixedWidth{}, Options{Header: true, MaxWidth: 40})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "name                 id                 \n\n" +
+		"web-production-fron… i-0123456789abcdef0\n" +
+		"東京-web             i-1                \n"
+	if buf.String() != expected {
+		t.Errorf("output got:\n%s, want:\n%s", buf.String(), expected)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if VisibleWidth(line) > 40 {
+			t.Errorf("line %q is %d wide, want at most 40", line, VisibleWidth(line))
+		}
+	}
+}
+
+func TestRenderMaxWidthKeep(t *testing.T) {
+	wideTable := New([]string{"name", "id"})
+	_ = wideTable.AddRow([]string{"web-production-frontend-1", "i-0123456789abcdef0"}, []Tag{})
+	var buf bytes.Buffer
+	err := wideTable.Render(&buf, fixedWidth{}, Options{MaxWidth: 30, Keep: []string{"id"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "web-produ… i-0123456789abcdef0\n"
+	if buf.String() != expected {
+		t.Errorf("output got %q, want %q", buf.String(), expected)
+	}
+}
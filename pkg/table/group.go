@@ -0,0 +1,69 @@
+package table
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+type Group struct {
+	Key   string
+	Table FixedWidthFont
+}
+
+// GroupBy splits rows by the value in column, groups are ordered by that value
+// and rows keep their order within a group
+func (fwf FixedWidthFont) GroupBy(column string) ([]Group, error) {
+	index := fwf.columnIndex(column)
+	if index < 0 {
+		return nil, Error{Message: fmt.Sprintf("unknown group column %q", column)}
+	}
+	var groups = make([]Group, 0)
+	var positions = make(map[string]int)
+	for i, row := range fwf.Rows {
+		key := row[index]
+		position, ok := positions[key]
+		if !ok {
+			position = len(groups)
+			positions[key] = position
+			groups = append(groups, Group{Key: key, Table: New(fwf.Header)})
+		}
+		err := groups[position].Table.AddRow(row, fwf.Tags[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(groups, func(i int, j int) bool {
+		return CompareCells(groups[i].Key, groups[j].Key) < 0
+	})
+	return groups, nil
+}
+
+// RenderGroups renders each group under a title line giving the column, value
+// and row count, with the same column widths in every group
+func RenderGroups(w io.Writer, column string, groups []Group, renderer Renderer, opts Options) error {
+	if len(groups) > 0 {
+		var rows [][]string
+		for _, group := range groups {
+			rows = append(rows, group.Table.Rows...)
+		}
+		opts.widths = columnWidths(groups[0].Table.Header, rows)
+	}
+	for i, group := range groups {
+		if i > 0 {
+			_, err := fmt.Fprintln(w)
+			if err != nil {
+				return err
+			}
+		}
+		_, err := fmt.Fprintf(w, "%s: %s (%d)\n", column, group.Key, len(group.Table.Rows))
+		if err != nil {
+			return err
+		}
+		err = group.Table.Render(w, renderer, opts)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
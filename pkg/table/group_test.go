@@ -0,0 +1,46 @@
+package table
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestGroupBy(t *testing.T) {
+	groups, err := createSortTestTable().GroupBy("name")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	keys := make([]string, len(groups))
+	counts := make([]int, len(groups))
+	for i, group := range groups {
+		keys[i] = group.Key
+		counts[i] = len(group.Table.Rows)
+	}
+	if !reflect.DeepEqual(keys, []string{"db", "web"}) {
+		t.Errorf("keys got %v, want %v", keys, []string{"db", "web"})
+	}
+	if !reflect.DeepEqual(counts, []int{1, 2}) {
+		t.Errorf("counts got %v, want %v", counts, []int{1, 2})
+	}
+	if groups[1].Table.Tags[1][0].Value != "3" {
+		t.Errorf("tags got %v, want rows to keep their tags", groups[1].Table.Tags)
+	}
+	_, err = createSortTestTable().GroupBy("az")
+	if err == nil {
+		t.Errorf("expected an error, did not get one")
+	}
+}
+
+func TestRenderGroups(t *testing.T) {
+	groups, _ := createSortTestTable().GroupBy("name")
+	var buf bytes.Buffer
+	err := RenderGroups(&buf, "name", groups, fixedWidth{}, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "name: db (1)\ndb   10.0.0.9  2021-10-01T01:00:00\n\nname: web (2)\nweb  10.0.0.10 2021-09-26T19:21:42\nweb  -         2021-10-02T01:00:00\n"
+	if buf.String() != expected {
+		t.Errorf("output got %q, want %q", buf.String(), expected)
+	}
+}
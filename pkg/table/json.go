@@ -0,0 +1,84 @@
+package table
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+func init() {
+	Register("json", jsonRenderer{})
+}
+
+// jsonRow keeps the keys in column order, a map would sort them. Missing
+// cells are null, tag values are always strings.
+type jsonRow struct {
+	header []string
+	row    []string
+	tags   []Tag
+}
+
+func (r jsonRow) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, cell := range r.row {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		if isMissing(cell) {
+			writeJSONKey(&buf, r.header[i])
+			buf.WriteString("null")
+			continue
+		}
+		writeJSONPair(&buf, r.header[i], cell)
+	}
+	if r.tags != nil {
+		if len(r.row) > 0 {
+			buf.WriteString(",")
+		}
+		writeJSONKey(&buf, "tags")
+		buf.WriteString("{")
+		for i, tag := range r.tags {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			writeJSONPair(&buf, tag.Key, tag.Value)
+		}
+		buf.WriteString("}")
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+func writeJSONKey(buf *bytes.Buffer, key string) {
+	k, _ := json.Marshal(key)
+	buf.Write(k)
+	buf.WriteString(":")
+}
+
+func writeJSONPair(buf *bytes.Buffer, key string, value string) {
+	writeJSONKey(buf, key)
+	v, _ := json.Marshal(value)
+	buf.Write(v)
+}
+
+// jsonRenderer writes an array with an object per row keyed by the header, tags
+// are included as a nested object when opts.Tags is set. Keys are always present
+// so opts.Header is ignored.
+type jsonRenderer struct{}
+
+func (jsonRenderer) Render(w io.Writer, header []string, rows [][]string, tags [][]Tag, opts Options) error {
+	objects := make([]jsonRow, len(rows))
+	for i, row := range rows {
+		objects[i] = jsonRow{header: header, row: row}
+		if opts.Tags {
+			objects[i].tags = append([]Tag{}, tags[i]...)
+		}
+	}
+	out, err := json.MarshalIndent(objects, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(out, '\n'))
+	return err
+}
@@ -0,0 +1,76 @@
+package table
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPrintJSON(t *testing.T) {
+	var data = []struct {
+		testName string
+		withTags bool
+		expected string
+	}{
+		{"without tags", false, `[
+  {
+    "name": "web \"1\"",
+    "id": "i-1"
+  },
+  {
+    "name": "db",
+    "id": null
+  }
+]
+`},
+		{"with tags", true, `[
+  {
+    "name": "web \"1\"",
+    "id": "i-1",
+    "tags": {
+      "Name": "web \"1\"",
+      "Owner": "alice"
+    }
+  },
+  {
+    "name": "db",
+    "id": null,
+    "tags": {}
+  }
+]
+`},
+	}
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			jsonTable := New([]string{"name", "id"})
+			_ = jsonTable.AddRow([]string{"web \"1\"", "i-1"}, []Tag{{"Name", "web \"1\""}, {"Owner", "alice"}})
+			_ = jsonTable.AddRow([]string{"db", "-"}, []Tag{})
+			var buf bytes.Buffer
+			err := jsonTable.Render(&buf, jsonRenderer{}, Options{Header: true, Tags: d.withTags})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != d.expected {
+				t.Errorf("output got %s, want %s", buf.String(), d.expected)
+			}
+		})
+	}
+}
+
+func TestPrintJSONEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	emptyTable := New([]string{"name"})
+	err := emptyTable.Render(&buf, jsonRenderer{}, Options{Header: true, Tags: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded []map[string]interface{}
+	err = json.Unmarshal(buf.Bytes(), &decoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, []map[string]interface{}{}) {
+		t.Errorf("decoded got %v, want empty array", decoded)
+	}
+}
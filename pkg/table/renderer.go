@@ -0,0 +1,50 @@
+package table
+
+import (
+	"io"
+	"sort"
+)
+
+type Options struct {
+	Header bool
+	Tags   bool
+	// only used by the fixed width table, nil for plain text
+	Styler Styler
+	// fixed width tables wider than this have their widest columns cut, 0 for no limit
+	MaxWidth int
+	// headings of columns only cut once the others are as narrow as they go,
+	// such as ids and addresses that are no use shortened
+	Keep []string
+	// set by RenderGroups so every group lines up, measured from the rows when nil
+	widths []int
+}
+
+// Renderer writes rows of cells, each row with its own tags, in some output format
+type Renderer interface {
+	Render(w io.Writer, header []string, rows [][]string, tags [][]Tag, opts Options) error
+}
+
+var renderers = make(map[string]Renderer)
+
+// Register makes a renderer available by name, typically from an init function
+func Register(name string, renderer Renderer) {
+	renderers[name] = renderer
+}
+
+func Lookup(name string) (Renderer, bool) {
+	renderer, ok := renderers[name]
+	return renderer, ok
+}
+
+func Names() []string {
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (fwf FixedWidthFont) Render(w io.Writer, renderer Renderer, opts Options) error {
+	return renderer.Render(w, fwf.Header, fwf.Rows, fwf.Tags, opts)
+}
@@ -0,0 +1,71 @@
+package table
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestNames(t *testing.T) {
+	expected := []string{"csv", "json", "table", "tsv"}
+	if !reflect.DeepEqual(Names(), expected) {
+		t.Errorf("names got %v, want %v", Names(), expected)
+	}
+}
+
+// every registered renderer must pass these
+func TestRendererConformance(t *testing.T) {
+	for _, name := range Names() {
+		renderer, _ := Lookup(name)
+		t.Run(name+" includes every cell", func(t *testing.T) {
+			var buf bytes.Buffer
+			err := createTestTable().Render(&buf, renderer, Options{Header: true})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, row := range createTestTable().Rows {
+				for _, cell := range row {
+					if !strings.Contains(buf.String(), cell) {
+						t.Errorf("output %q missing cell %q", buf.String(), cell)
+					}
+				}
+			}
+			if !strings.Contains(buf.String(), "heading2") {
+				t.Errorf("output %q missing header", buf.String())
+			}
+		})
+		t.Run(name+" includes tags only when asked", func(t *testing.T) {
+			var without, with bytes.Buffer
+			_ = createTestTable().Render(&without, renderer, Options{})
+			_ = createTestTable().Render(&with, renderer, Options{Tags: true})
+			if strings.Contains(without.String(), "value2") {
+				t.Errorf("output %q has tags, want none", without.String())
+			}
+			if !strings.Contains(with.String(), "value2") {
+				t.Errorf("output %q missing tags", with.String())
+			}
+		})
+		t.Run(name+" renders an empty table", func(t *testing.T) {
+			var buf bytes.Buffer
+			emptyTable := New([]string{"a"})
+			err := emptyTable.Render(&buf, renderer, Options{Tags: true})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+		t.Run(name+" reports write errors", func(t *testing.T) {
+			err := createTestTable().Render(failingWriter{}, renderer, Options{Header: true, Tags: true})
+			if err == nil {
+				t.Errorf("expected an error, did not get one")
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package table
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+	"utils/aws/pkg/duration"
+)
+
+type SortKey struct {
+	Column     string
+	Descending bool
+}
+
+// ParseSortKeys parses a comma separated list of columns, each optionally
+// prefixed with - for descending order, e.g. name,-launched
+func ParseSortKeys(s string) ([]SortKey, error) {
+	var keys = make([]SortKey, 0, 2)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		key := SortKey{Column: strings.TrimPrefix(item, "-"), Descending: strings.HasPrefix(item, "-")}
+		if key.Column == "" {
+			return nil, Error{Message: fmt.Sprintf("empty sort column in %q", s)}
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Sort orders rows by each key in turn. Empty cells, or -, come last whatever the direction.
+func (fwf *FixedWidthFont) Sort(keys []SortKey) error {
+	indexes := make([]int, len(keys))
+	for i, key := range keys {
+		index := fwf.columnIndex(key.Column)
+		if index < 0 {
+			return Error{Message: fmt.Sprintf("unknown sort column %q", key.Column)}
+		}
+		indexes[i] = index
+	}
+	fwf.SortRows(func(a []string, b []string) bool {
+		for i, key := range keys {
+			x, y := a[indexes[i]], b[indexes[i]]
+			if isMissing(x) || isMissing(y) {
+				if isMissing(x) == isMissing(y) {
+					continue
+				}
+				return isMissing(y)
+			}
+			c := CompareCells(x, y)
+			if c == 0 {
+				continue
+			}
+			return (c < 0) != key.Descending
+		}
+		return false
+	})
+	return nil
+}
+
+func (fwf FixedWidthFont) columnIndex(column string) int {
+	for i, heading := range fwf.Header {
+		if heading == column {
+			return i
+		}
+	}
+	return -1
+}
+
+func isMissing(cell string) bool {
+	return cell == "" || cell == "-"
+}
+
+var timeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05"}
+
+// CompareCells compares as ip addresses, numbers, durations or times when both
+// cells parse as one, otherwise as strings
+func CompareCells(a string, b string) int {
+	if ipA, ipB := net.ParseIP(a), net.ParseIP(b); ipA != nil && ipB != nil {
+		return bytes.Compare(ipA.To16(), ipB.To16())
+	}
+	if numA, errA := strconv.ParseFloat(a, 64); errA == nil {
+		if numB, errB := strconv.ParseFloat(b, 64); errB == nil {
+			switch {
+			case numA < numB:
+				return -1
+			case numA > numB:
+				return 1
+			}
+			return 0
+		}
+	}
+	if durationA, errA := duration.Parse(a); errA == nil {
+		if durationB, errB := duration.Parse(b); errB == nil {
+			switch {
+			case durationA < durationB:
+				return -1
+			case durationA > durationB:
+				return 1
+			}
+			return 0
+		}
+	}
+	for _, layout := range timeLayouts {
+		timeA, errA := time.Parse(layout, a)
+		timeB, errB := time.Parse(layout, b)
+		if errA == nil && errB == nil {
+			switch {
+			case timeA.Before(timeB):
+				return -1
+			case timeA.After(timeB):
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,118 @@
+package table
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSortKeys(t *testing.T) {
+	var data = []struct {
+		s        string
+		expected []SortKey
+		err      string
+	}{
+		{"name", []SortKey{{Column: "name"}}, ""},
+		{"name,-launched", []SortKey{{Column: "name"}, {Column: "launched", Descending: true}}, ""},
+		{"name,", nil, "empty sort column"},
+		{"-", nil, "empty sort column"},
+	}
+	for _, d := range data {
+		t.Run(d.s, func(t *testing.T) {
+			keys, err := ParseSortKeys(d.s)
+			if d.err != "" {
+				if err == nil || !strings.Contains(err.Error(), d.err) {
+					t.Fatalf("err got %v, want %q", err, d.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(keys, d.expected) {
+				t.Errorf("keys got %+v, want %+v", keys, d.expected)
+			}
+		})
+	}
+}
+
+func TestCompareCells(t *testing.T) {
+	var data = []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"web", "db", 1},
+		{"10.0.0.9", "10.0.0.10", -1},
+		{"10.0.0.1", "10.0.0.1", 0},
+		{"9", "10", -1},
+		{"2.5", "2.50", 0},
+		{"2021-09-26T19:21:42", "2021-10-01T01:00:00", -1},
+		{"2021-09-26T19:21:42Z", "2021-09-26T20:21:42+02:00", 1},
+		{"10.0.0.9", "web", -1},
+		{"3d4h", "12h", 1},
+		{"90m", "1h30m", 0},
+	}
+	for _, d := range data {
+		t.Run(d.a+" "+d.b, func(t *testing.T) {
+			if c := CompareCells(d.a, d.b); c != d.expected {
+				t.Errorf("compare got %d, want %d", c, d.expected)
+			}
+		})
+	}
+}
+
+func createSortTestTable() FixedWidthFont {
+	sortTable := New([]string{"name", "ip", "launched"})
+	sortTable.AddRow([]string{"web", "10.0.0.10", "2021-09-26T19:21:42"}, []Tag{{Key: "n", Value: "1"}})
+	sortTable.AddRow([]string{"db", "10.0.0.9", "2021-10-01T01:00:00"}, []Tag{{Key: "n", Value: "2"}})
+	sortTable.AddRow([]string{"web", "-", "2021-10-02T01:00:00"}, []Tag{{Key: "n", Value: "3"}})
+	return sortTable
+}
+
+func TestSort(t *testing.T) {
+	var data = []struct {
+		keys         []SortKey
+		expectedTags []string
+	}{
+		{[]SortKey{{Column: "name"}}, []string{"2", "1", "3"}},
+		{[]SortKey{{Column: "name"}, {Column: "launched", Descending: true}}, []string{"2", "3", "1"}},
+		{[]SortKey{{Column: "ip"}}, []string{"2", "1", "3"}},
+		{[]SortKey{{Column: "ip", Descending: true}}, []string{"1", "2", "3"}},
+	}
+	for _, d := range data {
+		t.Run(prettySortKeys(d.keys), func(t *testing.T) {
+			sortTable := createSortTestTable()
+			err := sortTable.Sort(d.keys)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tags := make([]string, len(sortTable.Tags))
+			for i, rowTags := range sortTable.Tags {
+				tags[i] = rowTags[0].Value
+			}
+			if !reflect.DeepEqual(tags, d.expectedTags) {
+				t.Errorf("row order got %v, want %v", tags, d.expectedTags)
+			}
+		})
+	}
+}
+
+func TestSortUnknownColumn(t *testing.T) {
+	sortTable := createSortTestTable()
+	err := sortTable.Sort([]SortKey{{Column: "age"}})
+	if err == nil || err.Error() != "unknown sort column \"age\"" {
+		t.Errorf("err got %v, want unknown sort column", err)
+	}
+}
+
+func prettySortKeys(keys []SortKey) string {
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = key.Column
+		if key.Descending {
+			names[i] = "-" + names[i]
+		}
+	}
+	return strings.Join(names, ",")
+}
@@ -11,11 +11,10 @@ import (
 
 func TestNew(t *testing.T) {
 	var data = []struct {
-		header         []string
-		expectedWidths []int
+		header []string
 	}{
-		{[]string{"a", "b"}, []int{1, 1}},
-		{[]string{"123", "456789"}, []int{3, 6}},
+		{[]string{"a", "b"}},
+		{[]string{"123", "456789"}},
 	}
 	for _, d := range data {
 		t.Run(strings.Join(d.header, " "), func(t *testing.T) {
@@ -23,8 +22,8 @@ func TestNew(t *testing.T) {
 			if !reflect.DeepEqual(fwfTable.Header, d.header) {
 				t.Errorf("Header got %+v, want %+v", fwfTable.Header, d.header)
 			}
-			if !reflect.DeepEqual(fwfTable.widths, d.expectedWidths) {
-				t.Errorf("widths got %+v, want %+v", fwfTable.widths, d.expectedWidths)
+			if len(fwfTable.Rows) != 0 || len(fwfTable.Tags) != 0 {
+				t.Errorf("Rows got %+v and Tags %+v, want empty", fwfTable.Rows, fwfTable.Tags)
 			}
 		})
 	}
@@ -32,20 +31,19 @@ func TestNew(t *testing.T) {
 
 func TestAddRow(t *testing.T) {
 	var data = []struct {
-		header         []string
-		rows           [][]string
-		expectedWidths []int
+		header []string
+		rows   [][]string
 	}{
 		{[]string{"col1", "col2"}, [][]string{
 			{"row1", "row1"},
-		}, []int{4, 4}},
+		}},
 		{[]string{"col", "col"}, [][]string{
 			{"longer", "moar width here!"},
-		}, []int{6, 16}},
+		}},
 		{[]string{"a", "b"}, [][]string{
 			{"some text", "other text!"},
 			{"short", "x"},
-		}, []int{9, 11}},
+		}},
 	}
 	for _, d := range data {
 		t.Run(strings.Join(d.header, " "), func(t *testing.T) {
@@ -59,9 +57,6 @@ func TestAddRow(t *testing.T) {
 			if !reflect.DeepEqual(fwfTable.Header, d.header) {
 				t.Errorf("Header got %+v, want %+v", fwfTable.Header, d.header)
 			}
-			if !reflect.DeepEqual(fwfTable.widths, d.expectedWidths) {
-				t.Errorf("widths got %+v, want %+v", fwfTable.widths, d.expectedWidths)
-			}
 			if !reflect.DeepEqual(fwfTable.Rows, d.rows) {
 				t.Errorf("Rows got %+v, want %+v", fwfTable.Rows, d.rows)
 			}
@@ -102,26 +97,25 @@ func TestAddRowErrors(t *testing.T) {
 
 func TestAddRowTags(t *testing.T) {
 	var data = []struct {
-		tags                    [][]Tag
-		expectedMaxTagKeyLength int
+		tags [][]Tag
 	} {
 		{[][]Tag{
 			{},
-		}, 0},
+		}},
 		{[][]Tag{
 			{Tag{Key: "k", Value: "v"}},
-		}, 1},
+		}},
 		{[][]Tag{
 			{Tag{Key: "1", Value: "v"}},
 			{Tag{Key: "22", Value: "v"}},
-		}, 2},
+		}},
 		{[][]Tag{
 			{Tag{Key: "longer", Value: "v"}},
 			{Tag{Key: "short", Value: "v"}},
-		}, 6},
+		}},
 	}
 	for _, d := range data {
-		t.Run("maxTagKeyLength", func(t *testing.T) {
+		t.Run("tags", func(t *testing.T) {
 			fwfTable := New([]string{"one"})
 			for _, tags := range d.tags {
 				err := fwfTable.AddRow([]string{"row"}, tags)
@@ -129,9 +123,6 @@ func TestAddRowTags(t *testing.T) {
 					t.Fatalf("error adding row: %v", err)
 				}
 			}
-			if fwfTable.maxTagKeyLength != d.expectedMaxTagKeyLength {
-				t.Errorf("maxTagKeyLength got %d, want %d", fwfTable.maxTagKeyLength, d.expectedMaxTagKeyLength)
-			}
 			if !reflect.DeepEqual(fwfTable.Tags, d.tags) {
 				t.Errorf("Tags got %+v, want %+v", fwfTable.Tags, d.tags)
 			}
@@ -170,4 +161,20 @@ func TestPrint(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestSortRows(t *testing.T) {
+	fwfTable := createTestTable()
+	fwfTable.AddRow([]string{"r0c1", "x", "0"}, []Tag{{Key: "k0", Value: "v0"}})
+	fwfTable.SortRows(func(a []string, b []string) bool {
+		return a[2] < b[2]
+	})
+	expectedRows := [][]string{{"r0c1", "x", "0"}, {"r1c1", "more", "1"}, {"r2c1", "cellr2", "2"}}
+	if !reflect.DeepEqual(fwfTable.Rows, expectedRows) {
+		t.Errorf("Rows got %+v, want %+v", fwfTable.Rows, expectedRows)
+	}
+	expectedTags := [][]Tag{{{Key: "k0", Value: "v0"}}, {{Key: "k", Value: "val1"}}, {{Key: "key", Value: "value"}, {Key: "longerkey", Value: "value2"}}}
+	if !reflect.DeepEqual(fwfTable.Tags, expectedTags) {
+		t.Errorf("Tags got %+v, want %+v", fwfTable.Tags, expectedTags)
+	}
+}
@@ -0,0 +1,56 @@
+package table
+
+import "strings"
+
+// FilterTags drops the tags whose key keep rejects
+func (fwf *FixedWidthFont) FilterTags(keep func(key string) bool) {
+	for i, tags := range fwf.Tags {
+		kept := make([]Tag, 0, len(tags))
+		for _, tag := range tags {
+			if keep(tag.Key) {
+				kept = append(kept, tag)
+			}
+		}
+		fwf.Tags[i] = kept
+	}
+}
+
+// TagsAsColumns moves tags into a column per key, - where a row does not have the tag
+func (fwf *FixedWidthFont) TagsAsColumns() {
+	keys := tagKeys(fwf.Tags)
+	fwf.appendColumns(keys, func(row int) []string {
+		cells := make([]string, len(keys))
+		for i, key := range keys {
+			cells[i] = "-"
+			for _, tag := range fwf.Tags[row] {
+				if tag.Key == key {
+					cells[i] = tag.Value
+				}
+			}
+		}
+		return cells
+	})
+}
+
+// TagsAsColumn moves tags into a single column as k=v,k=v
+func (fwf *FixedWidthFont) TagsAsColumn(heading string) {
+	fwf.appendColumns([]string{heading}, func(row int) []string {
+		pairs := make([]string, len(fwf.Tags[row]))
+		for i, tag := range fwf.Tags[row] {
+			pairs[i] = tag.Key + "=" + tag.Value
+		}
+		if len(pairs) == 0 {
+			return []string{"-"}
+		}
+		return []string{strings.Join(pairs, ",")}
+	})
+}
+
+// rows lose their tags, they are now in the columns
+func (fwf *FixedWidthFont) appendColumns(headings []string, cells func(row int) []string) {
+	rebuilt := New(append(append([]string{}, fwf.Header...), headings...))
+	for i, row := range fwf.Rows {
+		_ = rebuilt.AddRow(append(append([]string{}, row...), cells(i)...), []Tag{})
+	}
+	*fwf = rebuilt
+}
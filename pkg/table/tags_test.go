@@ -0,0 +1,48 @@
+package table
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFilterTags(t *testing.T) {
+	tagsTable := createTestTable()
+	tagsTable.FilterTags(func(key string) bool {
+		return strings.HasPrefix(key, "k")
+	})
+	expectedTags := [][]Tag{{{Key: "k", Value: "val1"}}, {{Key: "key", Value: "value"}}}
+	if !reflect.DeepEqual(tagsTable.Tags, expectedTags) {
+		t.Errorf("tags got %+v, want %+v", tagsTable.Tags, expectedTags)
+	}
+}
+
+func TestTagsAsColumns(t *testing.T) {
+	tagsTable := createTestTable()
+	tagsTable.TagsAsColumns()
+	expectedHeader := []string{"a", "heading2", "3", "k", "key", "longerkey"}
+	if !reflect.DeepEqual(tagsTable.Header, expectedHeader) {
+		t.Errorf("header got %v, want %v", tagsTable.Header, expectedHeader)
+	}
+	expectedRows := [][]string{{"r1c1", "more", "1", "val1", "-", "-"}, {"r2c1", "cellr2", "2", "-", "value", "value2"}}
+	if !reflect.DeepEqual(tagsTable.Rows, expectedRows) {
+		t.Errorf("rows got %v, want %v", tagsTable.Rows, expectedRows)
+	}
+	if !reflect.DeepEqual(tagsTable.Tags, [][]Tag{{}, {}}) {
+		t.Errorf("tags got %v, want none", tagsTable.Tags)
+	}
+}
+
+func TestTagsAsColumn(t *testing.T) {
+	tagsTable := createTestTable()
+	_ = tagsTable.AddRow([]string{"r3c1", "x", "3"}, []Tag{})
+	tagsTable.TagsAsColumn("tags")
+	expectedRows := [][]string{
+		{"r1c1", "more", "1", "k=val1"},
+		{"r2c1", "cellr2", "2", "key=value,longerkey=value2"},
+		{"r3c1", "x", "3", "-"},
+	}
+	if !reflect.DeepEqual(tagsTable.Rows, expectedRows) {
+		t.Errorf("rows got %v, want %v", tagsTable.Rows, expectedRows)
+	}
+}